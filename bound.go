@@ -0,0 +1,152 @@
+package window
+
+import (
+	"fmt"
+	"time"
+)
+
+// BoundKind tells how a window bound is defined
+type BoundKind int
+
+const (
+	KindNone            BoundKind = iota // no bound, ex: the right bound of a sliding window
+	KindAbsolute                         // "2 April 2022"
+	KindRelativeToOther                  // "3 days"
+	KindRelativeToNow                    // "2 days ago", "last june"
+)
+
+func (k BoundKind) String() string {
+	switch k {
+	case KindAbsolute:
+		return "absolute"
+	case KindRelativeToOther:
+		return "relative"
+	case KindRelativeToNow:
+		return "relative_to_now"
+	}
+	return "none"
+}
+
+// Bound is a left or a right bound of a specification: AbsoluteBound, RelativeToOtherBound or RelativeToNowBound
+type Bound interface {
+	Kind() BoundKind
+	String() string // the bound in the grammar
+	isBound()
+}
+
+// AbsoluteBound is a time point: "2 April 2022"
+type AbsoluteBound struct {
+	Time time.Time
+}
+
+// RelativeToOtherBound is a period applied to the other bound: "WITHIN 3 days"
+type RelativeToOtherBound struct {
+	Period Period
+}
+
+// RelativeToNowBound is defined relatively to the time the specification is resolved at.
+// It is either a period ("yesterday", "last week", "next june") when Verbal is set, or a point ("2 days ago").
+// Numbered periods ("2022-W23", "Q1 2022") do not depend on now, but they are resolved with the Calendar as well.
+type RelativeToNowBound struct {
+	Verbal    string // "today", "yesterday", "tomorrow", "now" or a period word after "last"/"next": "week", "june"
+	Future    bool   // "next week", "2 days later"
+	Period    Period // the distance from now for points: "2 days ago"
+	Round     string // the unit a point is rounded to in date math: "day" in "now-7d/d"
+	Count     int    // the number of units: 3 in "last 3 weeks", Verbal is the unit then
+	Full      bool   // "last 3 full weeks" are whole calendar weeks, otherwise the weeks are counted from now
+	This      bool   // "this week" is the calendar period which contains now, Verbal is the unit
+	Year      int    // the year of a numbered period "2022-W23", Verbal is the unit
+	Index     int    // the number of a period in the year: 23 in "2022-W23"
+	Fiscal    bool   // a quarter or a half of a fiscal year: "fiscal Q1 2023", see Calendar.FiscalYearStart
+	TimeOfDay string // a point of a day period: "09:00:00" in "yesterday at 9am" or "end of day"
+	// Anchor is the bound a point is counted from instead of now: "last monday" in "3 days before last monday".
+	// It is either an AbsoluteBound or a RelativeToNowBound.
+	Anchor Bound
+}
+
+func (AbsoluteBound) Kind() BoundKind        { return KindAbsolute }
+func (RelativeToOtherBound) Kind() BoundKind { return KindRelativeToOther }
+func (RelativeToNowBound) Kind() BoundKind   { return KindRelativeToNow }
+
+func (b AbsoluteBound) String() string        { return formatAbs(b.Time) }
+func (b RelativeToOtherBound) String() string { return b.Period.String() }
+func (b RelativeToNowBound) String() string   { return b.internal().String() }
+
+func (AbsoluteBound) isBound()        {}
+func (RelativeToOtherBound) isBound() {}
+func (RelativeToNowBound) isBound()   {}
+
+func (b RelativeToNowBound) internal() *boundRelativeToNow {
+	relN := &boundRelativeToNow{inFuture: b.Future, verbal: b.Verbal, round: b.Round, count: b.Count, full: b.Full,
+		this: b.This, year: b.Year, index: b.Index, fiscal: b.Fiscal, timeOfDay: b.TimeOfDay}
+	if b.Verbal == "" {
+		relN.duration = b.Period
+	}
+	if b.Anchor != nil {
+		abs, _, anchorRelN, _ := readBound(b.Anchor) // an anchor of another kind fails the validation
+		relN.anchor = &boundAnchor{abs: abs, relN: anchorRelN}
+	}
+	return relN
+}
+
+// makeBound converts an internal bound to the exported one, it returns nil if there is no bound
+func makeBound(abs *time.Time, rel *Period, relN *boundRelativeToNow) Bound {
+	switch {
+	case abs != nil:
+		return AbsoluteBound{Time: *abs}
+	case rel != nil:
+		return RelativeToOtherBound{Period: *rel}
+	case relN != nil:
+		b := RelativeToNowBound{Verbal: relN.verbal, Future: relN.inFuture, Period: relN.duration, Round: relN.round,
+			Count: relN.count, Full: relN.full, This: relN.this, Year: relN.year, Index: relN.index, Fiscal: relN.fiscal,
+			TimeOfDay: relN.timeOfDay}
+		if relN.anchor != nil {
+			b.Anchor = makeBound(relN.anchor.abs, nil, relN.anchor.relN)
+		}
+		return b
+	}
+	return nil
+}
+
+// readBound converts an exported bound to the internal one
+func readBound(b Bound) (abs *time.Time, rel *Period, relN *boundRelativeToNow, err error) {
+	switch v := b.(type) {
+	case nil:
+	case AbsoluteBound:
+		abs = &v.Time
+	case RelativeToOtherBound:
+		rel = &v.Period
+	case RelativeToNowBound:
+		relN = v.internal()
+		err = relN.validate()
+	default:
+		err = fmt.Errorf("unsupported bound %T", b)
+	}
+	return
+}
+
+// Left return the left bound of the specification, nil for session windows
+func (s Specification) Left() Bound {
+	return makeBound(s.leftBoundAbs, s.leftBoundRel, s.leftBoundRelN)
+}
+
+// Right return the right bound of the specification, nil for sliding and session windows
+func (s Specification) Right() Bound {
+	return makeBound(s.rightBoundAbs, s.rightBoundRel, s.rightBoundRelN)
+}
+
+// NewSpecification makes a specification of the given bounds, the right bound is nil for sliding windows.
+// It validates the bounds the same way Start does.
+func NewSpecification(left, right Bound) (s Specification, err error) {
+	if left == nil {
+		return s, ErrEmptyWindow
+	}
+	if s.leftBoundAbs, s.leftBoundRel, s.leftBoundRelN, err = readBound(left); err != nil {
+		return
+	}
+	if s.rightBoundAbs, s.rightBoundRel, s.rightBoundRelN, err = readBound(right); err != nil {
+		return
+	}
+	err = s.validate()
+	return
+}
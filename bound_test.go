@@ -0,0 +1,76 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestSpecification_Bounds(t *testing.T) {
+	type test struct {
+		text        string
+		left, right Bound
+	}
+	tests := []test{
+		{"1 April 2022 within 1 day", AbsoluteBound{Time: dateparse.MustParse("1 April 2022")}, RelativeToOtherBound{Period: Period{Days: 1}}},
+		{"30 days", RelativeToOtherBound{Period: Period{Days: 30}}, nil},
+		{"from yesterday to next week", RelativeToNowBound{Verbal: "yesterday"}, RelativeToNowBound{Verbal: "week", Future: true}},
+		{"2 days ago to 1 hour later", RelativeToNowBound{Period: Period{Days: 2}}, RelativeToNowBound{Period: Period{Duration: time.Hour}, Future: true}},
+		{"session gap 1 hour", nil, nil},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec.Left() != tt.left {
+				t.Errorf("left bound [%v] should be [%v]", spec.Left(), tt.left)
+			}
+			if spec.Right() != tt.right {
+				t.Errorf("right bound [%v] should be [%v]", spec.Right(), tt.right)
+			}
+			if tt.left == nil {
+				return
+			}
+
+			// transform back
+			rebuilt, err := NewSpecification(spec.Left(), spec.Right())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !rebuilt.Equal(spec) {
+				t.Errorf("rebuilt specification [%s] should be [%s]", rebuilt, spec)
+			}
+		})
+	}
+}
+
+func TestBound_Kind(t *testing.T) {
+	spec, _ := Start("from 2 days ago within 1 day")
+	if k := spec.Left().Kind(); k != KindRelativeToNow || k.String() != "relative_to_now" {
+		t.Errorf("unexpected left bound kind %s", k)
+	}
+	if k := spec.Right().Kind(); k != KindRelativeToOther || k.String() != "relative" {
+		t.Errorf("unexpected right bound kind %s", k)
+	}
+	if s := spec.Left().String(); s != "2 days AGO" {
+		t.Errorf("unexpected left bound text %s", s)
+	}
+}
+
+func TestNewSpecification_Fail(t *testing.T) {
+	if _, err := NewSpecification(RelativeToOtherBound{Period: Period{Days: 1}}, RelativeToOtherBound{Period: Period{Days: 1}}); !errors.Is(err, ErrTwoRelBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrTwoRelBounds)
+	}
+	if _, err := NewSpecification(nil, RelativeToNowBound{Verbal: "today"}); !errors.Is(err, ErrEmptyWindow) {
+		t.Errorf("error [%v] should be [%v]", err, ErrEmptyWindow)
+	}
+	if _, err := NewSpecification(RelativeToNowBound{Verbal: "fortnight"}, nil); err == nil {
+		t.Errorf("unknown verbal should not be accepted")
+	}
+}
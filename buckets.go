@@ -0,0 +1,109 @@
+package window
+
+import (
+	"time"
+)
+
+// alignToStep returns the closest calendar boundary before t which suits the step, in the location of t:
+// the beginning of the year for yearly steps, of the month (quarter, half-year) for monthly steps, of the week (see
+// Calendar.WeekStart) for weekly steps, of the day for daily steps. Clock steps are aligned to multiples of the step since
+// the beginning of the day.
+func alignToStep(t time.Time, step Period, cal Calendar) time.Time {
+	y, m, d := t.Date()
+	switch {
+	case step.Years != 0 && step.Months == 0:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	case step.Years != 0 || step.Months != 0:
+		if step.Years == 0 && 12%step.Months == 0 { // quarters and halves start in the first month of the year
+			m -= (m - 1) % time.Month(step.Months)
+		}
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	case step.Days != 0 && step.Days%7 == 0:
+		return cal.startOfWeek(t)
+	case step.Days != 0:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return midnight.Add(t.Sub(midnight) / step.Duration * step.Duration)
+}
+
+// SplitOption configures the alignment of buckets, see Window.Split
+type SplitOption func(*splitConfig)
+
+type splitConfig struct {
+	calendar Calendar
+}
+
+// SplitWithCalendar aligns weekly buckets to the week start of the given calendar instead of ISOCalendar
+func SplitWithCalendar(c Calendar) SplitOption {
+	return func(config *splitConfig) { config.calendar = c }
+}
+
+// Split divides the window into adjoining buckets of the given step aligned to calendar boundaries in the
+// location of the window: hourly buckets start at the beginning of an hour, daily buckets at midnight,
+// weekly buckets on Calendar.WeekStart (Monday unless another Calendar is given: SplitWithCalendar(USCalendar)),
+// monthly buckets on the first day of a month and so on. See Buckets.
+func (w *Window) Split(step Period, opts ...SplitOption) ([]*Window, error) {
+	if w.from == nil {
+		return nil, ErrSlidingHasNoBounds
+	}
+	if !isPositive(step) {
+		return nil, ErrInvalidStep
+	}
+	config := splitConfig{calendar: ISOCalendar}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return w.Buckets(step, alignToStep(*w.from, step, config.calendar))
+}
+
+// Buckets divides the window into adjoining buckets which edges are at alignment+k*step for any integer k.
+// Buckets are half-open [from, to), except the first and the last ones which are clipped by the window and inherit
+// its bounds inclusivity. A closed right bound that falls on a bucket edge does not produce a zero-length bucket,
+// but a closed window of a single point [x, x] is a single bucket [x, x].
+// Calendar steps are applied with Period.AddTo, so daily buckets last 23 or 25 hours around DST transitions, while
+// clock steps always have the same length.
+func (w *Window) Buckets(step Period, alignment time.Time) ([]*Window, error) {
+	from, to, err := w.edges()
+	if err != nil {
+		return nil, err
+	}
+	if !isPositive(step) {
+		return nil, ErrInvalidStep
+	}
+
+	if from.t.Equal(to.t) {
+		return []*Window{windowFromEdges(from, to)}, nil
+	}
+
+	k := stepIndex(alignment, step, from.t)
+	var buckets []*Window
+	for left := from; left.t.Before(to.t); k++ {
+		right := edge{step.Times(k + 1).AddTo(alignment), true}
+		if !endsBefore(right, to) {
+			right = to
+		}
+		buckets = append(buckets, windowFromEdges(left, right))
+		left = right.complement()
+	}
+	return buckets, nil
+}
+
+// stepIndex returns k such that alignment+k*step <= t < alignment+(k+1)*step
+func stepIndex(alignment time.Time, step Period, t time.Time) int {
+	// the approximation makes it close to the real one
+	k := int(t.Sub(alignment) / step.Approximate())
+	for step.Times(k).AddTo(alignment).After(t) {
+		k--
+	}
+	for !step.Times(k + 1).AddTo(alignment).After(t) {
+		k++
+	}
+	return k
+}
+
+// isPositive returns true if the period moves time forward
+func isPositive(p Period) bool {
+	return !p.IsZero() && p.Years >= 0 && p.Months >= 0 && p.Days >= 0 && p.Duration >= 0
+}
@@ -0,0 +1,132 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func bucketsToString(buckets []*Window, layout string) string {
+	parts := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		l, r := "[", "]"
+		if b.fromExcluded {
+			l = "("
+		}
+		if b.toExcluded {
+			r = ")"
+		}
+		parts = append(parts, l+b.from.Format(layout)+","+b.to.Format(layout)+r)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestWindow_Split(t *testing.T) {
+	type test struct {
+		text   string
+		step   Period
+		layout string
+		result string
+	}
+	tests := []test{
+		{"1 Apr 2022 10:30 to 1 Apr 2022 13:00", Period{Duration: time.Hour}, "15:04",
+			"[10:30,11:00) [11:00,12:00) [12:00,13:00)"},
+		{"1 Apr 2022 10:30 to 1 Apr 2022 12:10", Period{Duration: 45 * time.Minute}, "15:04",
+			"[10:30,11:15) [11:15,12:00) [12:00,12:10]"},
+		{"(1 Apr 2022 10:30 to 1 Apr 2022 12:10)", Period{Duration: time.Hour}, "15:04",
+			"(10:30,11:00) [11:00,12:00) [12:00,12:10)"},
+		{"1 Jan 2022 to 15 Apr 2022", Period{Months: 1}, "2 Jan",
+			"[1 Jan,1 Feb) [1 Feb,1 Mar) [1 Mar,1 Apr) [1 Apr,15 Apr]"},
+		{"1 May 2022 to 10 May 2022", Period{Days: 7}, "2 Jan",
+			"[1 May,2 May) [2 May,9 May) [9 May,10 May]"},
+		{"15 Nov 2021 to 15 Mar 2022", Period{Months: 3}, "2 Jan 2006",
+			"[15 Nov 2021,1 Jan 2022) [1 Jan 2022,15 Mar 2022]"},
+		{"15 Nov 2021 to 15 Mar 2023", Period{Years: 1}, "2 Jan 2006",
+			"[15 Nov 2021,1 Jan 2022) [1 Jan 2022,1 Jan 2023) [1 Jan 2023,15 Mar 2023]"},
+		{"1 Apr 2022 10:30 to 1 Apr 2022 10:30", Period{Duration: time.Hour}, "15:04", "[10:30,10:30]"},
+		{"1 Apr 2022 11:00 to 1 Apr 2022 11:00", Period{Duration: time.Hour}, "15:04", "[11:00,11:00]"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			buckets, err := resolveWindow(t, tt.text).Split(tt.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := bucketsToString(buckets, tt.layout); r != tt.result {
+				t.Errorf("buckets [%s] should be [%s]", r, tt.result)
+			}
+		})
+	}
+}
+
+func TestWindow_SplitWithCalendar(t *testing.T) {
+	w := resolveWindow(t, "1 May 2022 to 10 May 2022") // Sunday
+	weeks, err := w.Split(Period{Days: 7}, SplitWithCalendar(USCalendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, expected := bucketsToString(weeks, "2 Jan"), "[1 May,8 May) [8 May,10 May]"; r != expected {
+		t.Errorf("buckets [%s] should be [%s]", r, expected)
+	}
+}
+
+func TestWindow_SplitDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 13 March 2022 02:00 EST clocks jumped to 03:00 EDT
+	w := windowFromEdges(
+		edge{t: time.Date(2022, 3, 12, 0, 0, 0, 0, ny)},
+		edge{t: time.Date(2022, 3, 15, 0, 0, 0, 0, ny), excluded: true},
+	)
+	days, err := w.Split(Period{Days: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 3 || days[1].Duration() != 23*time.Hour || days[2].Duration() != 24*time.Hour {
+		t.Errorf("unexpected daily buckets [%s]", bucketsToString(days, time.RFC3339))
+	}
+
+	w = windowFromEdges(
+		edge{t: time.Date(2022, 3, 13, 0, 0, 0, 0, ny)},
+		edge{t: time.Date(2022, 3, 13, 5, 0, 0, 0, ny), excluded: true},
+	)
+	hours, err := w.Split(Period{Duration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := bucketsToString(hours, "15:04"); r != "[00:00,01:00) [01:00,03:00) [03:00,04:00) [04:00,05:00)" {
+		t.Errorf("unexpected hourly buckets [%s]", r)
+	}
+}
+
+func TestWindow_Buckets(t *testing.T) {
+	w := resolveWindow(t, "1 Apr 2022 10:00 to 1 Apr 2022 11:00")
+	buckets, err := w.Buckets(Period{Duration: 20 * time.Minute}, dateparse.MustParse("1 Apr 2022 00:05"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := bucketsToString(buckets, "15:04"); r != "[10:00,10:05) [10:05,10:25) [10:25,10:45) [10:45,11:00]" {
+		t.Errorf("unexpected buckets [%s]", r)
+	}
+
+	// alignment after the window
+	buckets, _ = w.Buckets(Period{Duration: 20 * time.Minute}, dateparse.MustParse("2 Apr 2022 00:05"))
+	if r := bucketsToString(buckets, "15:04"); r != "[10:00,10:05) [10:05,10:25) [10:25,10:45) [10:45,11:00]" {
+		t.Errorf("unexpected buckets [%s]", r)
+	}
+
+	if _, err = w.Split(Period{}); !errors.Is(err, ErrInvalidStep) {
+		t.Errorf("error [%v] should be [%v]", err, ErrInvalidStep)
+	}
+	if _, err = resolveWindow(t, "30 days").Split(Period{Days: 1}); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+}
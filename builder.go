@@ -0,0 +1,235 @@
+package window
+
+import (
+	"fmt"
+	"time"
+)
+
+// Unit is a period word used in "last X" and "next X" bounds
+type Unit string
+
+const (
+	Nanosecond  Unit = "nanosecond"
+	Microsecond Unit = "microsecond"
+	Millisecond Unit = "millisecond"
+	Second      Unit = "second"
+	Minute      Unit = "minute"
+	Hour        Unit = "hour"
+	Day         Unit = "day"
+	Week        Unit = "week"
+	Month       Unit = "month"
+	Quarter     Unit = "quarter"
+	Half        Unit = "half"
+	Year        Unit = "year"
+
+	Monday    Unit = "monday"
+	Tuesday   Unit = "tuesday"
+	Wednesday Unit = "wednesday"
+	Thursday  Unit = "thursday"
+	Friday    Unit = "friday"
+	Saturday  Unit = "saturday"
+	Sunday    Unit = "sunday"
+
+	January   Unit = "january"
+	February  Unit = "february"
+	March     Unit = "march"
+	April     Unit = "april"
+	May       Unit = "may"
+	June      Unit = "june"
+	July      Unit = "july"
+	August    Unit = "august"
+	September Unit = "september"
+	October   Unit = "october"
+	November  Unit = "november"
+	December  Unit = "december"
+)
+
+// Abs makes an absolute bound: "2 April 2022"
+func Abs(t time.Time) AbsoluteBound { return AbsoluteBound{Time: t} }
+
+// Rel makes a bound relative to the other bound: "WITHIN 3 days"
+func Rel(p Period) RelativeToOtherBound { return RelativeToOtherBound{Period: p} }
+
+// Ago makes a point in the past relative to now: "2 days ago" is Ago(Period{Days: 2}). Clock time is kept as is,
+// so Ago(Period{Duration: 48 * time.Hour}) is 48 hours ago, which is not the same across a DST transition.
+func Ago(p Period) RelativeToNowBound { return RelativeToNowBound{Period: p} }
+
+// Later makes a point in the future relative to now: "2 days later" is Later(Period{Days: 2}), see Ago
+func Later(p Period) RelativeToNowBound { return RelativeToNowBound{Period: p, Future: true} }
+
+// Before makes a point before the beginning of the anchor: "3 days before last monday"
+func Before(p Period, anchor Bound) RelativeToNowBound {
+	return RelativeToNowBound{Period: p, Anchor: anchor}
+}
+
+// After makes a point after the end of the anchor: "2 hours after tomorrow"
+func After(p Period, anchor Bound) RelativeToNowBound {
+	return RelativeToNowBound{Period: p, Future: true, Anchor: anchor}
+}
+
+// Last makes a period in the past relative to now: "last week"
+func Last(u Unit) RelativeToNowBound { return RelativeToNowBound{Verbal: string(u)} }
+
+// Next makes a period in the future relative to now: "next week"
+func Next(u Unit) RelativeToNowBound { return RelativeToNowBound{Verbal: string(u), Future: true} }
+
+// LastN makes a period of n units which ends now: "last 3 weeks"
+func LastN(n int, u Unit) RelativeToNowBound { return RelativeToNowBound{Verbal: string(u), Count: n} }
+
+// NextN makes a period of n units which starts now: "next 3 weeks"
+func NextN(n int, u Unit) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(u), Count: n, Future: true}
+}
+
+// LastFull makes a period of n whole units before the current one: "last 3 full weeks"
+func LastFull(n int, u Unit) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(u), Count: n, Full: true}
+}
+
+// NextFull makes a period of n whole units after the current one: "next 3 full weeks"
+func NextFull(n int, u Unit) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(u), Count: n, Full: true, Future: true}
+}
+
+// This makes the calendar period which contains now: "this week"
+func This(u Unit) RelativeToNowBound { return RelativeToNowBound{Verbal: string(u), This: true} }
+
+// WeekOf makes the numbered week of a year: "2022-W23"
+func WeekOf(year, week int) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(Week), Year: year, Index: week}
+}
+
+// QuarterOf makes the numbered quarter of a year: "2022-Q1"
+func QuarterOf(year, quarter int) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(Quarter), Year: year, Index: quarter}
+}
+
+// HalfOf makes the numbered half of a year: "2022-H2"
+func HalfOf(year, half int) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(Half), Year: year, Index: half}
+}
+
+// FiscalQuarterOf makes the numbered quarter of a fiscal year: "fiscal 2023-Q1", see Calendar.FiscalYearStart
+func FiscalQuarterOf(year, quarter int) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(Quarter), Year: year, Index: quarter, Fiscal: true}
+}
+
+// FiscalHalfOf makes the numbered half of a fiscal year: "fiscal 2023-H1", see Calendar.FiscalYearStart
+func FiscalHalfOf(year, half int) RelativeToNowBound {
+	return RelativeToNowBound{Verbal: string(Half), Year: year, Index: half, Fiscal: true}
+}
+
+// Now makes the "now" bound
+func Now() RelativeToNowBound { return RelativeToNowBound{Verbal: "now"} }
+
+// Today makes the "today" bound
+func Today() RelativeToNowBound { return RelativeToNowBound{Verbal: "today"} }
+
+// Yesterday makes the "yesterday" bound
+func Yesterday() RelativeToNowBound { return RelativeToNowBound{Verbal: "yesterday"} }
+
+// Tomorrow makes the "tomorrow" bound
+func Tomorrow() RelativeToNowBound { return RelativeToNowBound{Verbal: "tomorrow"} }
+
+// At makes a point of a day period at the given time since midnight: Yesterday().At(9 * time.Hour) is
+// "yesterday at 9am". Seconds are the smallest unit, the rest of the time is dropped.
+func (b RelativeToNowBound) At(clock time.Duration) RelativeToNowBound {
+	b.TimeOfDay = fmt.Sprintf("%02d:%02d:%02d", int(clock/time.Hour), int(clock%time.Hour/time.Minute),
+		int(clock%time.Minute/time.Second))
+	return b
+}
+
+// EndOfDay makes the end of a day period a point: Yesterday().EndOfDay() is "yesterday end of day"
+func (b RelativeToNowBound) EndOfDay() RelativeToNowBound {
+	b.TimeOfDay = endOfDay
+	return b
+}
+
+// Builder makes specifications in code the same way the parser does. Lengths are periods everywhere, the parser makes
+// calendar periods of days and longer units: "2 days" is Period{Days: 2}, not 48 hours.
+//
+//	window.From(window.Abs(t)).Within(window.Period{Duration: 24 * time.Hour}).Build()
+//	window.Since(window.Ago(window.Period{Days: 2})).Until(window.Last(window.Week)).Build()
+type Builder struct {
+	left, right                 Bound
+	leftExcluded, rightExcluded bool
+	step, sessionGap            *Period
+	location                    *time.Location
+}
+
+// From starts a specification with the left bound: "FROM x"
+func From(b Bound) *Builder { return &Builder{left: b} }
+
+// Since is the same as From
+func Since(b Bound) *Builder { return From(b) }
+
+// Within starts a specification with the left bound relative to the right one: "WITHIN 3 days [TO x]".
+// Without the right bound it is a sliding window.
+func Within(p Period) *Builder { return From(Rel(p)) }
+
+// ToDate makes a specification from the beginning of the current period to now: "MTD" is ToDate(Month)
+func ToDate(u Unit) *Builder {
+	return From(RelativeToNowBound{Round: string(u)}).To(Now())
+}
+
+// SessionGap starts a session window specification: "SESSION GAP 30 minutes"
+func SessionGap(gap Period) *Builder { return &Builder{sessionGap: &gap} }
+
+// To sets the right bound: "TO y"
+func (b *Builder) To(bound Bound) *Builder {
+	b.right = bound
+	return b
+}
+
+// Until is the same as To
+func (b *Builder) Until(bound Bound) *Builder { return b.To(bound) }
+
+// Within sets the right bound relative to the left one: "FROM x WITHIN 1 day"
+func (b *Builder) Within(p Period) *Builder { return b.To(Rel(p)) }
+
+// ExcludeFrom excludes the left bound from the window: "(FROM x TO y]"
+func (b *Builder) ExcludeFrom() *Builder {
+	b.leftExcluded = true
+	return b
+}
+
+// ExcludeTo excludes the right bound from the window: "[FROM x TO y)"
+func (b *Builder) ExcludeTo() *Builder {
+	b.rightExcluded = true
+	return b
+}
+
+// By sets the step of buckets: "BY 1 hour"
+func (b *Builder) By(step Period) *Builder {
+	b.step = &step
+	return b
+}
+
+// In sets the time zone the specification is resolved in: "IN Europe/Berlin"
+func (b *Builder) In(loc *time.Location) *Builder {
+	b.location = loc
+	return b
+}
+
+// Build validates and returns the specification
+func (b *Builder) Build() (Specification, error) {
+	if b.sessionGap != nil {
+		if b.left != nil || b.right != nil || b.step != nil {
+			return Specification{}, ErrSessionHasNoBounds
+		}
+		if !isPositive(*b.sessionGap) {
+			return Specification{}, ErrInvalidStep
+		}
+		return Specification{sessionGap: b.sessionGap}, nil
+	}
+
+	s, err := NewSpecification(b.left, b.right)
+	if err != nil {
+		return Specification{}, err
+	}
+	if b.step != nil && !isPositive(*b.step) {
+		return Specification{}, ErrInvalidStep
+	}
+	s.leftExcluded, s.rightExcluded, s.step, s.location = b.leftExcluded, b.rightExcluded, b.step, b.location
+	return s, nil
+}
@@ -0,0 +1,120 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	type test struct {
+		builder *Builder
+		text    string
+	}
+	tests := []test{
+		{From(Abs(dateparse.MustParse("2022-04-01"))).Within(Period{Days: 1}), "from 2022-04-01 within 1 day"},
+		{From(Abs(dateparse.MustParse("2022-04-01"))).Within(Period{Duration: 24 * time.Hour}), "from 2022-04-01 within 24 hours"},
+		{Since(Ago(Period{Days: 2})).Until(Last(Week)), "since 2 days ago until last week"},
+		{From(Yesterday()).To(Later(Period{Duration: time.Hour})), "from yesterday to 1 hour later"},
+		{From(Last(Monday)).To(Next(March)), "from last monday to next march"},
+		{Within(Period{Days: 7}).To(Today()), "within 7 days to today"},
+		{Within(Period{Duration: 30 * time.Minute}), "30 minutes"},
+		{From(LastFull(3, Week)).To(NextN(10, Day)), "from last 3 full weeks to next 10 days"},
+		{From(LastN(2, Hour)).To(NextFull(1, Month)), "from last 2 hours to next full month"},
+		{From(Yesterday()).To(Now()).ExcludeTo().By(Period{Duration: time.Hour}), "[from yesterday to now) by 1 hour"},
+		{From(Tomorrow()).ExcludeFrom().To(Next(Week)), "(from tomorrow to next week]"},
+		{SessionGap(Period{Duration: 30 * time.Minute}), "session gap 30 minutes"},
+		{ToDate(Month), "MTD"},
+		{From(Yesterday().At(22 * time.Hour)).To(Next(Friday).At(9*time.Hour + 30*time.Minute + 15*time.Second)),
+			"from yesterday 22:00 to next friday at 9:30:15"},
+		{From(Today()).To(Today().EndOfDay()).ExcludeTo(), "[from today to today end of day)"},
+		{From(Before(Period{Days: 3}, Last(Monday))).To(After(Period{Duration: 2 * time.Hour}, Tomorrow())),
+			"from 3 days before last monday to 2 hours after tomorrow"},
+		{From(After(Period{Duration: time.Hour}, Abs(dateparse.MustParse("2022-04-01")))).To(Now()), "from 1 hour after 2022-04-01 to now"},
+		{From(Yesterday()).To(Today()).In(berlin), "from yesterday to today in Europe/Berlin"},
+		{From(WeekOf(2022, 23)).To(Now()), "from 2022-W23 to now"},
+		{From(QuarterOf(2021, 3)).To(HalfOf(2022, 1)), "from Q3 2021 to H1 2022"},
+		{From(FiscalQuarterOf(2021, 3)).To(FiscalHalfOf(2022, 1)), "from fiscal 2021-Q3 to fiscal 2022-H1"},
+		{From(Last(Half)).To(Now()), "from last half to now"},
+		{From(This(Quarter)).To(Now()), "this quarter to now"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			built, err := tt.builder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !built.Equal(parsed) {
+				t.Errorf("built specification [%s] should be [%s]", built, parsed)
+			}
+		})
+	}
+}
+
+func TestBuilder_ClockDuration(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2022, 3, 14, 12, 0, 0, 0, newYork) // DST started on 2022-03-13
+	parsed, _ := Start("2 days ago to now")
+
+	type test struct {
+		builder  *Builder
+		equal    bool // the built specification is equal to the parsed one
+		expected time.Time
+	}
+	tests := []test{
+		{From(Ago(Period{Days: 2})).To(Now()), true, time.Date(2022, 3, 12, 12, 0, 0, 0, newYork)},
+		{From(Ago(Period{Duration: 48 * time.Hour})).To(Now()), false, time.Date(2022, 3, 12, 11, 0, 0, 0, newYork)},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			built, err := tt.builder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if built.Equal(parsed) != tt.equal {
+				t.Errorf("built specification [%s] equal to [%s] should be %t", built, parsed, tt.equal)
+			}
+			if from, _ := built.ResolveAt(now).GetBounds(); !from.Equal(tt.expected) {
+				t.Errorf("bound [%s] should be [%s]", from, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuilder_Fail(t *testing.T) {
+	type test struct {
+		builder *Builder
+		err     error
+	}
+	tests := []test{
+		{Within(Period{Duration: time.Hour}).Within(Period{Duration: time.Hour}), ErrTwoRelBounds},
+		{From(nil), ErrEmptyWindow},
+		{From(Today()).By(Period{}), ErrInvalidStep},
+		{SessionGap(Period{Duration: -time.Minute}), ErrInvalidStep},
+		{SessionGap(Period{Duration: time.Minute}).To(Today()), ErrSessionHasNoBounds},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			if _, err := tt.builder.Build(); !errors.Is(err, tt.err) {
+				t.Errorf("error [%v] should be [%v]", err, tt.err)
+			}
+		})
+	}
+
+	// anchors can't be relative to the other bound
+	if _, err := From(Before(Period{Duration: time.Hour}, Rel(Period{Duration: time.Hour}))).Build(); err == nil {
+		t.Errorf("anchor relative to the other bound should not be accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package window
+
+import "time"
+
+// Calendar tells how calendar periods are counted when a specification is resolved
+type Calendar struct {
+	// WeekStart is the first day of a week: "last week" and "this week" start on this day.
+	// Note that the zero value is Sunday.
+	WeekStart time.Weekday
+	// FirstWeekMinDays is the minimal number of days of a year in its first week, it defines week numbers:
+	// ISO 8601 requires 4 days (the first week contains the first Thursday), in the US the first week contains
+	// 1 January. Values below 1 are treated as 1.
+	FirstWeekMinDays int
+	// FiscalYearStart is the first month of a fiscal year, it is used by fiscal quarters and halves "fiscal Q1 2023".
+	// A fiscal year is numbered by the calendar year it starts in: with April, fiscal 2023 lasts from April 2023 to
+	// March 2024. The zero value is January.
+	FiscalYearStart time.Month
+}
+
+var (
+	// ISOCalendar counts weeks from Monday with ISO 8601 week numbers, it is used by default
+	ISOCalendar = Calendar{WeekStart: time.Monday, FirstWeekMinDays: 4}
+	// USCalendar counts weeks from Sunday, the first week of a year contains 1 January
+	USCalendar = Calendar{WeekStart: time.Sunday, FirstWeekMinDays: 1}
+)
+
+// ResolveOption configures the resolution of a specification, see Specification.ResolveAt
+type ResolveOption func(*resolveConfig)
+
+type resolveConfig struct {
+	calendar  Calendar
+	precision time.Duration
+}
+
+func makeResolveConfig(opts []ResolveOption) resolveConfig {
+	c := resolveConfig{calendar: ISOCalendar}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithCalendar resolves calendar periods with the given calendar instead of ISOCalendar
+func WithCalendar(c Calendar) ResolveOption {
+	return func(config *resolveConfig) { config.calendar = c }
+}
+
+// WithWeekStart resolves weeks starting on the given day, week numbers are counted as in ISOCalendar otherwise
+func WithWeekStart(d time.Weekday) ResolveOption {
+	return func(config *resolveConfig) { config.calendar.WeekStart = d }
+}
+
+// WithFiscalYearStart resolves fiscal quarters and halves in fiscal years starting in the given month
+func WithFiscalYearStart(m time.Month) ResolveOption {
+	return func(config *resolveConfig) { config.calendar.FiscalYearStart = m }
+}
+
+// WithPrecision truncates the resolve time to a multiple of d on the wall clock, so resolutions within the same
+// second (minute) return equal windows, which is handy for cache keys: WithPrecision(time.Second)
+func WithPrecision(d time.Duration) ResolveOption {
+	return func(config *resolveConfig) { config.precision = d }
+}
+
+// daysSinceWeekStart returns how many days passed since the beginning of the week which contains t
+func (c Calendar) daysSinceWeekStart(t time.Time) int {
+	return (int(t.Weekday()) - int(c.WeekStart) + 7) % 7
+}
+
+// startOfWeek returns the beginning of the week which contains t, in the location of t
+func (c Calendar) startOfWeek(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d-c.daysSinceWeekStart(t), 0, 0, 0, 0, t.Location())
+}
+
+// startOfNumberedWeek returns the beginning of the week with the given number in the year: "2022-W23".
+// Weeks past the end of the year continue into the next year.
+func (c Calendar) startOfNumberedWeek(year, week int, loc *time.Location) time.Time {
+	minDays := c.FirstWeekMinDays
+	if minDays < 1 {
+		minDays = 1
+	}
+	newYear := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	firstWeek := c.startOfWeek(newYear)
+	if 7-c.daysSinceWeekStart(newYear) < minDays { // too few days of the year in the week of 1 January
+		firstWeek = firstWeek.AddDate(0, 0, 7)
+	}
+	return firstWeek.AddDate(0, 0, (week-1)*7)
+}
+
+// startOfNumberedPeriod returns the beginning of the numbered period of a year: a week, a quarter or a half.
+// Fiscal quarters and halves are counted from Calendar.FiscalYearStart.
+func (c Calendar) startOfNumberedPeriod(unit string, year, index int, fiscal bool, loc *time.Location) time.Time {
+	if unit == "week" {
+		return c.startOfNumberedWeek(year, index, loc)
+	}
+	month := time.January
+	if fiscal && c.FiscalYearStart >= time.January && c.FiscalYearStart <= time.December {
+		month = c.FiscalYearStart
+	}
+	length := mapUnitToPeriod(unit)
+	return time.Date(year, month+time.Month((index-1)*length.Months), 1, 0, 0, 0, 0, loc)
+}
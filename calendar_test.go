@@ -0,0 +1,141 @@
+package window
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestCalendar_startOfNumberedWeek(t *testing.T) {
+	type test struct {
+		calendar   Calendar
+		year, week int
+		expected   string
+	}
+	tests := []test{
+		{ISOCalendar, 2022, 1, "2022-01-03"},
+		{ISOCalendar, 2021, 1, "2021-01-04"},
+		{ISOCalendar, 2020, 53, "2020-12-28"},
+		{ISOCalendar, 2026, 1, "2025-12-29"},
+		{ISOCalendar, 2022, 23, "2022-06-06"},
+		{USCalendar, 2022, 1, "2021-12-26"},
+		{USCalendar, 2023, 1, "2023-01-01"},
+		{Calendar{WeekStart: time.Sunday, FirstWeekMinDays: 4}, 2022, 23, "2022-06-05"},
+		{Calendar{}, 2022, 1, "2021-12-26"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			start := tt.calendar.startOfNumberedWeek(tt.year, tt.week, time.UTC)
+			if s := start.Format("2006-01-02"); s != tt.expected {
+				t.Errorf("week %d of %d should start on %s, got %s", tt.week, tt.year, tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestCalendar_startOfNumberedPeriod(t *testing.T) {
+	aprilFiscal := Calendar{FiscalYearStart: time.April}
+
+	type test struct {
+		calendar    Calendar
+		unit        string
+		year, index int
+		fiscal      bool
+		expected    string
+	}
+	tests := []test{
+		{ISOCalendar, "quarter", 2022, 1, false, "2022-01-01"},
+		{ISOCalendar, "quarter", 2022, 3, false, "2022-07-01"},
+		{ISOCalendar, "half", 2021, 2, false, "2021-07-01"},
+		{ISOCalendar, "quarter", 2022, 2, true, "2022-04-01"},
+		{ISOCalendar, "week", 2022, 23, false, "2022-06-06"},
+		{aprilFiscal, "quarter", 2023, 1, false, "2023-01-01"},
+		{aprilFiscal, "quarter", 2023, 1, true, "2023-04-01"},
+		{aprilFiscal, "quarter", 2023, 4, true, "2024-01-01"},
+		{aprilFiscal, "half", 2023, 2, true, "2023-10-01"},
+		{Calendar{FiscalYearStart: time.October}, "quarter", 2023, 2, true, "2024-01-01"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			start := tt.calendar.startOfNumberedPeriod(tt.unit, tt.year, tt.index, tt.fiscal, time.UTC)
+			if s := start.Format("2006-01-02"); s != tt.expected {
+				t.Errorf("%s %d of %d should start on %s, got %s", tt.unit, tt.index, tt.year, tt.expected, s)
+			}
+		})
+	}
+}
+
+func TestSpecification_ResolveWithCalendar(t *testing.T) {
+	now := dateparse.MustParse("2022-04-13 15:04:05") // Wednesday
+
+	type test struct {
+		text     string
+		opts     []ResolveOption
+		from, to string
+	}
+	tests := []test{
+		{"from last week to 2025-01-01", nil, "2022-04-04 00:00:00", "2025-01-01 00:00:00"},
+		{"from last week to 2025-01-01", []ResolveOption{WithCalendar(USCalendar)}, "2022-04-03 00:00:00", "2025-01-01 00:00:00"},
+		{"from next week to 2025-01-01", []ResolveOption{WithWeekStart(time.Saturday)}, "2022-04-16 00:00:00", "2025-01-01 00:00:00"},
+		{"[from 2022-01-01 to last week)", nil, "2022-01-01 00:00:00", "2022-04-11 00:00:00"},
+		{"from this week to 2025-01-01", []ResolveOption{WithCalendar(USCalendar)}, "2022-04-10 00:00:00", "2025-01-01 00:00:00"},
+		{"from last 2 full weeks to 2025-01-01", []ResolveOption{WithWeekStart(time.Sunday)}, "2022-03-27 00:00:00", "2025-01-01 00:00:00"},
+		{"from 2022-01-01 to now/w", []ResolveOption{WithWeekStart(time.Sunday)}, "2022-01-01 00:00:00", "2022-04-16 23:59:59.999999999"},
+		{"from 2022-W23 to 2025-01-01", nil, "2022-06-06 00:00:00", "2025-01-01 00:00:00"},
+		{"from week 23 of 2022 to 2025-01-01", []ResolveOption{WithWeekStart(time.Sunday)}, "2022-06-05 00:00:00", "2025-01-01 00:00:00"},
+		{"from 2022w23 to 2023-01-01", []ResolveOption{WithCalendar(USCalendar)}, "2022-05-29 00:00:00", "2023-01-01 00:00:00"},
+		{"from fiscal Q1 2023 to 2025-01-01", []ResolveOption{WithFiscalYearStart(time.April)}, "2023-04-01 00:00:00", "2025-01-01 00:00:00"},
+		{"from fiscal 2023-q4 to 2025-01-01", []ResolveOption{WithFiscalYearStart(time.April)}, "2024-01-01 00:00:00", "2025-01-01 00:00:00"},
+		{"from fiscal Q1 2022 to now", []ResolveOption{WithFiscalYearStart(time.April)}, "2022-04-01 00:00:00", "2022-04-13 15:04:05"},
+		{"from Q1 2023 to 2025-01-01", []ResolveOption{WithFiscalYearStart(time.April)}, "2023-01-01 00:00:00", "2025-01-01 00:00:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			winSpec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := winSpec.ResolveAt(now, tt.opts...).GetBounds()
+			expectedFrom, expectedTo := dateparse.MustParse(tt.from), dateparse.MustParse(tt.to)
+			if !from.Equal(expectedFrom) || !to.Equal(expectedTo) {
+				t.Errorf("window [%s, %s] should be [%s, %s]", from, to, expectedFrom, expectedTo)
+			}
+		})
+	}
+}
+
+func TestSpecification_ResolveNumberedWeek(t *testing.T) {
+	now := dateparse.MustParse("2022-04-13 15:04:05") // Wednesday
+
+	type test struct {
+		text     string
+		calendar Calendar
+		from, to string
+	}
+	tests := []test{
+		{"2022-W23", ISOCalendar, "2022-06-06 00:00:00", "2022-06-12 23:59:59.999999999"},
+		{"2022-W23", USCalendar, "2022-05-29 00:00:00", "2022-06-04 23:59:59.999999999"},
+		{"[week 23 of 2022)", ISOCalendar, "2022-06-06 00:00:00", "2022-06-13 00:00:00"},
+		{"from 2022-W15 to now", ISOCalendar, "2022-04-11 00:00:00", "2022-04-13 15:04:05"},
+		{"from 2022-W15 to now", USCalendar, "2022-04-03 00:00:00", "2022-04-13 15:04:05"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			winSpec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := winSpec.ResolveAt(now, WithCalendar(tt.calendar)).GetBounds()
+			expectedFrom, expectedTo := dateparse.MustParse(tt.from), dateparse.MustParse(tt.to)
+			if !from.Equal(expectedFrom) || !to.Equal(expectedTo) {
+				t.Errorf("window [%s, %s] should be [%s, %s]", from, to, expectedFrom, expectedTo)
+			}
+		})
+	}
+}
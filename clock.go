@@ -0,0 +1,65 @@
+package window
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time a specification is resolved at, see Specification.Resolve
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the clock of the system: time.Now
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// FakeClock is a clock which stands still until it is moved by hand, it is safe for concurrent use
+type FakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewFakeClock returns a clock stopped at t
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{t: t}
+}
+
+// Now returns the time the clock is stopped at
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// Advance moves the clock forward by d, or back if d is negative
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// Resolve is the same as ResolveAt at the current time of the clock
+func (s *Specification) Resolve(c Clock, opts ...ResolveOption) *Window {
+	return s.ResolveAt(c.Now(), opts...)
+}
+
+// TryResolve is the same as Resolve but returns an error instead of panicking
+func (s *Specification) TryResolve(c Clock, opts ...ResolveOption) (*Window, error) {
+	return s.TryResolveAt(c.Now(), opts...)
+}
+
+// ResolveNow is the same as ResolveAt at the current time of the system clock
+func (s *Specification) ResolveNow(opts ...ResolveOption) *Window {
+	return s.Resolve(SystemClock, opts...)
+}
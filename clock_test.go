@@ -0,0 +1,91 @@
+package window
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestFakeClock(t *testing.T) {
+	now := dateparse.MustParse("2022-04-13 15:04:05")
+	clock := NewFakeClock(now)
+	if !clock.Now().Equal(now) {
+		t.Errorf("clock [%s] should be [%s]", clock.Now(), now)
+	}
+
+	clock.Advance(90 * time.Minute)
+	if expected := now.Add(90 * time.Minute); !clock.Now().Equal(expected) {
+		t.Errorf("clock [%s] should be [%s]", clock.Now(), expected)
+	}
+
+	clock.Set(now.AddDate(0, 0, -1))
+	if expected := now.AddDate(0, 0, -1); !clock.Now().Equal(expected) {
+		t.Errorf("clock [%s] should be [%s]", clock.Now(), expected)
+	}
+}
+
+func TestSpecification_Resolve(t *testing.T) {
+	clock := NewFakeClock(dateparse.MustParse("2022-04-13 15:04:05.123456789"))
+
+	type test struct {
+		text     string
+		opts     []ResolveOption
+		from, to string
+	}
+	tests := []test{
+		{"from yesterday to today", nil, "2022-04-12 00:00:00", "2022-04-13 23:59:59.999999999"},
+		{"from 1 hour ago to now", nil, "2022-04-13 14:04:05.123456789", "2022-04-13 15:04:05.123456789"},
+		{"from 1 hour ago to now", []ResolveOption{WithPrecision(time.Second)}, "2022-04-13 14:04:05", "2022-04-13 15:04:05"},
+		{"from 1 hour ago to now", []ResolveOption{WithPrecision(time.Minute)}, "2022-04-13 14:04:00", "2022-04-13 15:04:00"},
+		{"from last week to now", []ResolveOption{WithPrecision(time.Hour), WithCalendar(USCalendar)},
+			"2022-04-03 00:00:00", "2022-04-13 15:00:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			winSpec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := winSpec.TryResolve(clock, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := w.GetBounds()
+			expectedFrom, expectedTo := dateparse.MustParse(tt.from), dateparse.MustParse(tt.to)
+			if !from.Equal(expectedFrom) || !to.Equal(expectedTo) {
+				t.Errorf("window [%s, %s] should be [%s, %s]", from, to, expectedFrom, expectedTo)
+			}
+			if !w.Equal(winSpec.Resolve(clock, tt.opts...)) {
+				t.Errorf("Resolve and TryResolve should return equal windows")
+			}
+		})
+	}
+}
+
+func TestSpecification_ResolveWithPrecision(t *testing.T) {
+	winSpec, _ := Start("from 30 minutes ago to now")
+	clock := NewFakeClock(dateparse.MustParse("2022-04-13 15:04:05"))
+
+	first := winSpec.Resolve(clock, WithPrecision(time.Second))
+	clock.Advance(999 * time.Millisecond)
+	if second := winSpec.Resolve(clock, WithPrecision(time.Second)); !second.Equal(first) {
+		t.Errorf("window [%s] should be [%s] within the same second", second, first)
+	}
+	clock.Advance(time.Millisecond)
+	if third := winSpec.Resolve(clock, WithPrecision(time.Second)); third.Equal(first) {
+		t.Errorf("window [%s] should move in the next second", third)
+	}
+}
+
+func TestSpecification_ResolveNow(t *testing.T) {
+	winSpec, _ := Start("from 1 hour ago to now")
+	before := time.Now()
+	from, to := winSpec.ResolveNow().GetBounds()
+	after := time.Now()
+	if to.Before(before) || to.After(after) || to.Sub(from) != time.Hour {
+		t.Errorf("window [%s, %s] should end now", from, to)
+	}
+}
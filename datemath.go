@@ -0,0 +1,369 @@
+package window
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+// dateMathUnits maps date math units to period words
+var dateMathUnits = map[string]string{
+	"y": "year", "q": "quarter", "M": "month", "w": "week", "d": "day", "h": "hour", "H": "hour", "m": "minute", "s": "second",
+}
+
+// getDateMathUnits returns a list of units that can be used in date math, the case matters: "M" is a month, "m" is a minute.
+// Quarters "q" are not supported by Grafana and Elasticsearch, they are used for "QTD" which is "FROM now/q TO now".
+func getDateMathUnits() []string {
+	return []string{"y", "q", "M", "w", "d", "h", "H", "m", "s"}
+}
+
+// dateMathUnit returns the date math unit for a period word, see dateMathUnits
+func dateMathUnit(word string) string {
+	for _, u := range getDateMathUnits() {
+		if dateMathUnits[u] == word {
+			return u
+		}
+	}
+	return ""
+}
+
+// dateMath is a date math expression: an anchor followed by offsets and an optional rounding, "now-7d/d".
+// Offsets are applied left to right like in Grafana/Elasticsearch: "now+1h-1d" is not "now-1d+1h" across a DST
+// transition and "now+1M-1M" is not "now" on January 31.
+type dateMath struct {
+	anchor *time.Time // "2022-04-01||", nil for "now"
+	ops    []Period   // "-7d+1h", an operation can have several units when it is printed: "-1M+3d"
+	round  string     // "/d" as a period word: "day"
+}
+
+// parseDateMathOps parses the offsets and the rounding which follow an anchor: "-7d+1h/d".
+// Units are matched in the original case of the text.
+func parseDateMathOps(p *Parser) (ops []Period, round string, err *ParseError) {
+	for {
+		opStart := p.pos
+		sign := p.expectAny([]string{"+", "-"})
+		if sign == "" {
+			break
+		}
+		num := p.consumeRE(`\d+`)
+		if num == "" {
+			err = newParseError(p.original, p.pos, "", "number")
+			return
+		}
+		n, intErr := strconv.Atoi(num)
+		if intErr != nil {
+			err = newParseError(p.original, p.pos-len(num), intErr.Error(), "number")
+			return
+		}
+		unit, unitErr := expectDateMathUnit(p)
+		if unitErr != nil {
+			err = unitErr
+			return
+		}
+		if sign == "-" {
+			n = -n
+		}
+		op, opErr := mapUnitToPeriod(unit).TryTimes(n)
+		if opErr != nil {
+			err = newParseError(p.original, opStart+1, opErr.Error(), "number")
+			return
+		}
+		ops = append(ops, op)
+	}
+
+	if p.expect("/") {
+		round, err = expectDateMathUnit(p)
+	}
+	return
+}
+
+// expectDateMathUnit consumes a date math unit and returns its period word
+func expectDateMathUnit(p *Parser) (string, *ParseError) {
+	if !p.isEof() {
+		if word, ok := dateMathUnits[p.original[p.pos:p.pos+1]]; ok {
+			p.pos++
+			return word, nil
+		}
+	}
+	return "", newParseError(p.original, p.pos, "", getDateMathUnits()...)
+}
+
+// parseDateMath parses the whole text as a date math expression: "now-7d/d", "2022-04-01||+1M/M" or a date
+func parseDateMath(text string) (e dateMath, err *ParseError) {
+	p := startParsing(strings.TrimSpace(text))
+	if !p.expect("now") {
+		dateText, sep := p.consumeUntil([]string{"||"})
+		anchor, dateErr := dateparse.ParseStrict(dateText)
+		if dateErr != nil {
+			err = newParseError(p.original, 0, dateErr.Error(), "now", "date")
+			return
+		}
+		e.anchor = &anchor
+		if sep == "" { // a date without operations
+			return
+		}
+		p.expect(sep)
+	}
+
+	if e.ops, e.round, err = parseDateMathOps(p); err != nil {
+		return
+	}
+	if !p.isEof() {
+		expected := []string{"+", "-"}
+		if e.round == "" {
+			expected = append(expected, "/")
+		}
+		err = newParseError(p.original, p.pos, "", append(expected, "end of text")...)
+	}
+	return
+}
+
+// resolveRounding picks the edge of the period of the given unit which contains t.
+// Date math rounds the left bound down and the right bound up: "now/d" is the beginning of today on the left
+// and the end of today on the right (the next day start, if the bound is excluded).
+func resolveRounding(t time.Time, round string, isLeftBound, isExcluded bool, cal Calendar) time.Time {
+	if round == "" {
+		return t
+	}
+	length := mapUnitToPeriod(round)
+	start := alignToStep(t, length, cal)
+	if isLeftBound {
+		return start
+	}
+	next := length.AddTo(start)
+	if isExcluded {
+		return next
+	}
+	return next.Add(-time.Nanosecond)
+}
+
+// makeDateMathBound converts the offsets of an expression relative to now to a bound.
+// An offset joins the previous ones when Period.AddTo applies their sum the same way, otherwise the previous ones
+// become the anchor of the rest: "now-1M+1d" is a single point and "now-1y-6M" is 6 months before 1 year ago.
+// Offsets going back in time are kept as "X ago", so "now-2d" equals "2 days ago".
+func makeDateMathBound(ops []Period, round string) *boundRelativeToNow {
+	var anchor *boundRelativeToNow
+	var offset Period
+	for _, op := range ops {
+		if op.IsZero() {
+			continue
+		}
+		if _, last := addToSteps(offset); !offset.IsZero() && !joinsOffset(last, op) {
+			anchor, offset = makeDateMathPoint(anchor, offset, ""), Period{}
+		}
+		offset = offset.Plus(op)
+	}
+	return makeDateMathPoint(anchor, offset, round)
+}
+
+// makeDateMathPoint makes a point at the offset from the anchor, the anchor is now if it is nil
+func makeDateMathPoint(anchor *boundRelativeToNow, offset Period, round string) *boundRelativeToNow {
+	var b *boundRelativeToNow
+	switch neg := offset.Neg(); {
+	case offset.IsZero() && anchor != nil:
+		anchor.round = round
+		return anchor
+	case offset.IsZero() && round == "":
+		return &boundRelativeToNow{verbal: "now"}
+	case isPositive(neg):
+		b = &boundRelativeToNow{duration: neg, round: round}
+	default:
+		b = &boundRelativeToNow{inFuture: !offset.IsZero(), duration: offset, round: round}
+	}
+	if anchor != nil {
+		b.anchor = &boundAnchor{relN: anchor}
+	}
+	return b
+}
+
+// addToSteps returns the first and the last step of Period.AddTo which the period takes: 1 for years and months,
+// 2 for days and 3 for the clock, zeros for an empty period
+func addToSteps(p Period) (first, last int) {
+	for step, used := range []bool{p.Years != 0 || p.Months != 0, p.Days != 0, p.Duration != 0} {
+		if used {
+			if first == 0 {
+				first = step + 1
+			}
+			last = step + 1
+		}
+	}
+	return
+}
+
+// joinsOffset returns true if an operation gives the same result when it is summed with the previous offsets which
+// end with the given step of Period.AddTo. Months are clamped to the end of a month, so they are never summed.
+func joinsOffset(lastStep int, op Period) bool {
+	first, _ := addToSteps(op)
+	return first > lastStep || first == lastStep && first != 1
+}
+
+// isDateMath returns true if the bound can only be printed in date math: it is rounded or one of its offsets goes in
+// both directions
+func (b boundRelativeToNow) isDateMath() bool {
+	if _, ok := b.dateMathOps(); !ok || b.verbal != "" {
+		return false
+	}
+	return b.round != "" || !b.duration.IsZero() && !isPositive(b.duration) ||
+		b.anchor != nil && b.anchor.relN.isDateMath()
+}
+
+// dateMathOps returns the offsets from now of a point in the order they are applied, false if the point is counted
+// from a period or an absolute time
+func (b boundRelativeToNow) dateMathOps() ([]Period, bool) {
+	if b.verbal == "now" && b.anchor == nil {
+		return nil, true
+	}
+	if b.verbal != "" {
+		return nil, false
+	}
+	var ops []Period
+	if b.anchor != nil {
+		if b.anchor.relN == nil || b.anchor.relN.round != "" {
+			return nil, false
+		}
+		var ok bool
+		if ops, ok = b.anchor.relN.dateMathOps(); !ok {
+			return nil, false
+		}
+	}
+	return append(ops, b.offset()), true
+}
+
+// offset returns the signed distance from now of a point bound
+func (b boundRelativeToNow) offset() Period {
+	if b.inFuture {
+		return b.duration
+	}
+	return b.duration.Neg()
+}
+
+// formatDateMathOffset returns the period as date math operations: "-1M+3d", false if the period has
+// sub-second components
+func formatDateMathOffset(p Period) (string, bool) {
+	var text string
+	add := func(n int64, unit string) {
+		if n > 0 {
+			text += fmt.Sprintf("+%d%s", n, unit)
+		} else if n < 0 {
+			text += fmt.Sprintf("%d%s", n, unit)
+		}
+	}
+	if p.Months != 0 { // a single operation of months, see joinsOffset
+		add(int64(p.Years*12+p.Months), "M")
+	} else {
+		add(int64(p.Years), "y")
+	}
+	add(int64(p.Days), "d")
+	add(int64(p.Duration/time.Hour), "h")
+	add(int64(p.Duration%time.Hour/time.Minute), "m")
+	add(int64(p.Duration%time.Minute/time.Second), "s")
+	return text, p.Duration%time.Second == 0
+}
+
+// String returns the expression in date math: "now-7d/d", "2022-04-01T00:00:00Z||+1M"
+func (e dateMath) String() string {
+	text := "now"
+	if e.anchor != nil {
+		text = formatAbs(*e.anchor)
+	}
+	var offset string
+	for _, op := range e.ops {
+		text, _ := formatDateMathOffset(op)
+		offset += text
+	}
+	if e.anchor != nil && (offset != "" || e.round != "") {
+		text += "||"
+	}
+	text += offset
+	if e.round != "" {
+		text += "/" + dateMathUnit(e.round)
+	}
+	return text
+}
+
+// StartDateMath makes a specification of a Grafana/Elasticsearch style time range: StartDateMath("now-7d/d", "now/d").
+// Each bound is an anchor ("now" or a date followed by "||") with optional offsets of y, M, w, d, h (H), m and s units
+// and an optional rounding to a unit in the end. The left bound is rounded down and the right one is rounded up, so
+// the range from "now-7d/d" to "now/d" covers 8 whole days. Both bounds are included.
+//
+// Expressions relative to now become bounds relative to now ("now-2d" is "2 days ago"), expressions with a date
+// anchor are computed right away (weeks start on Monday) and become absolute bounds.
+func StartDateMath(from, to string) (s Specification, err error) {
+	for _, b := range []struct {
+		text string
+		side BoundSide
+		abs  **time.Time
+		relN **boundRelativeToNow
+	}{
+		{from, SideLeft, &s.leftBoundAbs, &s.leftBoundRelN},
+		{to, SideRight, &s.rightBoundAbs, &s.rightBoundRelN},
+	} {
+		e, parseErr := parseDateMath(b.text)
+		if parseErr != nil {
+			parseErr.Side = b.side
+			return Specification{}, parseErr
+		}
+		if e.anchor == nil {
+			*b.relN = makeDateMathBound(e.ops, e.round)
+			continue
+		}
+		t := *e.anchor
+		for _, op := range e.ops {
+			t = op.AddTo(t)
+		}
+		t = resolveRounding(t, e.round, b.side == SideLeft, false, ISOCalendar)
+		*b.abs = &t
+	}
+	err = s.validate()
+	return
+}
+
+// DateMath returns the specification as a Grafana/Elasticsearch style time range, see StartDateMath.
+// Only closed windows of absolute bounds, "now", points relative to now ("2 days ago") and date math bounds can be
+// expressed, a bound relative to the other one is merged into it when the other one is not rounded.
+// Periods ("yesterday", "last week") are not converted to date math rounding, so they are not supported.
+// Date math has no time zone, so bounds relative to now of a specification with a time zone can't be expressed
+// either. ErrNoDateMath is returned for anything else.
+func (s Specification) DateMath() (from, to string, err error) {
+	if s.sessionGap != nil || s.leftExcluded || s.rightExcluded {
+		return "", "", ErrNoDateMath
+	}
+	if s.location != nil && (s.leftBoundRelN != nil || s.rightBoundRelN != nil) {
+		return "", "", ErrNoDateMath
+	}
+	left, leftOk := makeDateMath(s.leftBoundAbs, s.leftBoundRelN)
+	right, rightOk := makeDateMath(s.rightBoundAbs, s.rightBoundRelN)
+
+	switch {
+	case s.leftBoundRel != nil && rightOk && right.round == "":
+		left, leftOk = right, true
+		left.ops = append(right.ops[:len(right.ops):len(right.ops)], s.leftBoundRel.Neg())
+	case s.rightBoundRel != nil && leftOk && left.round == "":
+		right, rightOk = left, true
+		right.ops = append(left.ops[:len(left.ops):len(left.ops)], *s.rightBoundRel)
+	}
+	if !leftOk || !rightOk {
+		return "", "", ErrNoDateMath
+	}
+	for _, op := range append(left.ops, right.ops...) {
+		if _, ok := formatDateMathOffset(op); !ok {
+			return "", "", ErrNoDateMath
+		}
+	}
+	return left.String(), right.String(), nil
+}
+
+// makeDateMath converts a bound to date math, false if it is not possible
+func makeDateMath(abs *time.Time, relN *boundRelativeToNow) (dateMath, bool) {
+	switch {
+	case abs != nil:
+		return dateMath{anchor: abs}, true
+	case relN == nil || relN.round == "quarter":
+		return dateMath{}, false
+	}
+	ops, ok := relN.dateMathOps()
+	return dateMath{ops: ops, round: relN.round}, ok
+}
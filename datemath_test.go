@@ -0,0 +1,149 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestStartDateMath(t *testing.T) {
+	// zone-less absolute bounds are parsed in the local zone
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	now := dateparse.MustParse("2022-04-13 15:04:05") // Wednesday
+	type test struct {
+		from, to             string
+		expectedL, expectedR string
+	}
+	tests := []test{
+		{"now-7d/d", "now/d", "2022-04-06 00:00:00", "2022-04-13 23:59:59.999999999"},
+		{"now-7d", "now", "2022-04-06 15:04:05", "2022-04-13 15:04:05"},
+		{"now/w", "now/w", "2022-04-11 00:00:00", "2022-04-17 23:59:59.999999999"},
+		{"now-1M/M", "now-1M/M", "2022-03-01 00:00:00", "2022-03-31 23:59:59.999999999"},
+		{"now-1y/y", "now+1h/h", "2021-01-01 00:00:00", "2022-04-13 16:59:59.999999999"},
+		{"now-1d+12H", "now+30m-1s", "2022-04-13 03:04:05", "2022-04-13 15:34:04"},
+		{"now-2w/d", "now+1s/s", "2022-03-30 00:00:00", "2022-04-13 15:04:06.999999999"},
+		{"2022-01-31||+1M", "2022-04-01||+1M/M", "2022-02-28 00:00:00", "2022-05-31 23:59:59.999999999"},
+		{"2022-01-01", " now ", "2022-01-01 00:00:00", "2022-04-13 15:04:05"},
+		{"2022-03-31||-1M+1d", "now-1d-1d+1h-30m", "2022-03-01 00:00:00", "2022-04-11 15:34:05"},
+		{"now-1y-6M", "2024-02-29||-1y-6M", "2020-10-13 15:04:05", "2022-08-28 00:00:00"},
+		{"2022-01-31||+1M-1M", "now+1h-1d", "2022-01-28 00:00:00", "2022-04-12 16:04:05"},
+		{"now-1M+1d-1M/d", "now+1h-1d+1d", "2022-02-14 00:00:00", "2022-04-13 16:04:05"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := StartDateMath(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := spec.ResolveAt(now).GetBounds()
+			if !from.Equal(dateparse.MustParse(tt.expectedL)) {
+				t.Errorf("left bound [%s] should be [%s]", from, tt.expectedL)
+			}
+			if !to.Equal(dateparse.MustParse(tt.expectedR)) {
+				t.Errorf("right bound [%s] should be [%s]", to, tt.expectedR)
+			}
+		})
+	}
+}
+
+func TestStartDateMath_SameAsGrammar(t *testing.T) {
+	spec, err := StartDateMath("now-7d", "now")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := Start("from 7 days ago to now")
+	if !spec.Equal(expected) {
+		t.Errorf("specification [%s] should be [%s]", spec, expected)
+	}
+}
+
+func TestStartDateMath_Fail(t *testing.T) {
+	type test struct {
+		from, to string
+		side     BoundSide
+		offset   int
+	}
+	tests := []test{
+		{"now-7x", "now", SideLeft, 5},
+		{"now", "now-d", SideRight, 4},
+		{"now/d+1h", "now", SideLeft, 5},
+		{"now-1d", "yesterday", SideRight, 0},
+		{"2022-01-01||-", "now", SideLeft, 13},
+		{"now-9999999999999h", "now", SideLeft, 4},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			_, err := StartDateMath(tt.from, tt.to)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error [%v] should be a ParseError", err)
+			}
+			if parseErr.Side != tt.side || parseErr.Offset != tt.offset {
+				t.Errorf("error [%v] should be at the %s bound at %d", err, tt.side, tt.offset)
+			}
+		})
+	}
+}
+
+func TestSpecification_DateMath(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	type test struct {
+		text     string
+		from, to string
+	}
+	tests := []test{
+		{"from now-7d/d to now/d", "now-7d/d", "now/d"},
+		{"from 2 days ago to 1 hour later", "now-2d", "now+1h"},
+		{"from 3 months ago within 1 week", "now-3M", "now-3M+7d"},
+		{"within 90 minutes until now", "now-1h-30m", "now"},
+		{"1 April 2022 within 1 day", "2022-04-01T00:00:00Z", "2022-04-01T00:00:00Z||+1d"},
+		{"within 1 year to now-1M", "now-1M-1y", "now-1M"},
+		{"from now-1y-6M/d to now+1h-1d", "now-1y-6M/d", "now+1h-1d"},
+		{"from 3 days before 1 month ago to now", "now-1M-3d", "now"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to, err := spec.DateMath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("date math [%s, %s] should be [%s, %s]", from, to, tt.from, tt.to)
+			}
+
+			// parse back
+			now := time.Now()
+			parsed, err := StartDateMath(from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.ResolveAt(now).Equal(spec.ResolveAt(now)) {
+				t.Errorf("date math [%s, %s] should resolve as [%s]", from, to, spec)
+			}
+		})
+	}
+
+	for _, text := range []string{"from yesterday to today", "30 days", "[from now-1d to now)", "session gap 1 hour", "from 1 millisecond ago to now",
+		"from now-1d to now in Europe/Berlin"} {
+		spec, _ := Start(text)
+		if _, _, err := spec.DateMath(); !errors.Is(err, ErrNoDateMath) {
+			t.Errorf("error [%v] for [%s] should be [%v]", err, text, ErrNoDateMath)
+		}
+	}
+}
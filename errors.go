@@ -0,0 +1,130 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrBoundsOrder is returned when the resolved left bound is after the right bound
+	ErrBoundsOrder = errors.New("window bounds are in wrong order")
+	// ErrEmptyWindow is returned when a window has neither bounds nor a slide
+	ErrEmptyWindow = errors.New("empty window")
+	// ErrTwoRelBounds is returned when both bounds of a specification are relative to each other
+	ErrTwoRelBounds = errors.New("two rel bound are not allowed")
+	// ErrSlidingHasNoBounds is returned when absolute bounds are requested from a sliding window
+	ErrSlidingHasNoBounds = errors.New("absolute bound are not defined on this window")
+	// ErrSessionHasNoBounds is returned when a session specification is resolved, see SessionWindower
+	ErrSessionHasNoBounds = errors.New("session window can't be resolved")
+	// ErrNotSession is returned when a session specification is expected
+	ErrNotSession = errors.New("specification is not a session window")
+	// ErrLateEvent is returned when an event is older than the lateness allows
+	ErrLateEvent = errors.New("event is too late")
+	// ErrNotSliding is returned when a sliding specification is expected
+	ErrNotSliding = errors.New("specification is not a sliding window")
+	// ErrInvalidStep is returned when a window is split with a step which does not move time forward
+	ErrInvalidStep = errors.New("step must be positive")
+	// ErrNoDateMath is returned when a specification can not be expressed in date math, see Specification.DateMath
+	ErrNoDateMath = errors.New("specification can't be expressed in date math")
+	// ErrNoISO is returned when a specification can not be expressed as an ISO 8601 interval, see Specification.ISO
+	ErrNoISO = errors.New("specification can't be expressed in ISO 8601")
+	// ErrNegativeCount is returned when a negative number of windows is requested, see Recurrence.Windows
+	ErrNegativeCount = errors.New("number of windows must not be negative")
+	// ErrPeriodOverflow is returned when a period is too long for its components, see Period.TryTimes
+	ErrPeriodOverflow = errors.New("period is too long")
+)
+
+// BoundSide tells which bound of a window is meant
+type BoundSide int
+
+const (
+	SideLeft BoundSide = iota
+	SideRight
+)
+
+func (s BoundSide) String() string {
+	if s == SideRight {
+		return "right"
+	}
+	return "left"
+}
+
+// ParseError describes a place in the text where the recognition failed
+type ParseError struct {
+	Text         string    // the whole text being recognized
+	Offset       int       // byte offset of the offending token in Text
+	Line, Column int       // 1-based position of the offending token
+	Token        string    // the offending token, empty at the end of the text
+	Expected     []string  // alternatives that would be accepted at Offset
+	Side         BoundSide // the bound being recognized
+	Reason       string    // optional details
+}
+
+func newParseError(text string, offset int, reason string, expected ...string) *ParseError {
+	e := &ParseError{
+		Text:     text,
+		Offset:   offset,
+		Line:     1 + strings.Count(text[:offset], "\n"),
+		Column:   offset - strings.LastIndex(text[:offset], "\n"),
+		Token:    regexp.MustCompile(`^\S*`).FindString(text[offset:]),
+		Expected: expected,
+		Reason:   reason,
+	}
+	return e
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("failed to recognize the %s bound at %d:%d", e.Side, e.Line, e.Column)
+	if e.Token != "" {
+		msg += fmt.Sprintf(": unexpected %q", e.Token)
+	} else {
+		msg += ": unexpected end of text"
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	if len(e.Expected) > 0 {
+		msg += ", expected " + strings.Join(e.Expected, ", ")
+	}
+	if len(e.Text) > 0 {
+		msg += "\n" + e.caret()
+	}
+	return msg
+}
+
+// caret renders the offending line with a caret under the offending token
+func (e *ParseError) caret() string {
+	lines := strings.Split(e.Text, "\n")
+	return fmt.Sprintf("%s\n%s^", lines[e.Line-1], strings.Repeat(" ", e.Column-1))
+}
+
+// merge returns the error that made the most progress in the text,
+// the expected alternatives of equally far errors are combined.
+func (e *ParseError) merge(other *ParseError) *ParseError {
+	switch {
+	case e == nil:
+		return other
+	case other == nil || other.Offset < e.Offset:
+		return e
+	case other.Offset > e.Offset:
+		return other
+	}
+
+	merged := *e
+	merged.Expected = append([]string{}, e.Expected...)
+	for _, alt := range other.Expected {
+		known := false
+		for _, m := range merged.Expected {
+			known = known || m == alt
+		}
+		if !known {
+			merged.Expected = append(merged.Expected, alt)
+		}
+	}
+	if merged.Reason == "" {
+		merged.Reason = other.Reason
+	}
+	return &merged
+}
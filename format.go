@@ -0,0 +1,234 @@
+package window
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns the period in the grammar of relative bounds: "1 year and 2 months and 3 hours"
+func (p Period) String() string {
+	var parts []string
+	add := func(n int64, unit string) {
+		if n == 1 || n == -1 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, unit))
+		} else if n != 0 {
+			parts = append(parts, fmt.Sprintf("%d %ss", n, unit))
+		}
+	}
+
+	add(int64(p.Years), "year")
+	add(int64(p.Months), "month")
+	add(int64(p.Days), "day")
+	d := p.Duration
+	for _, u := range []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+		{"millisecond", time.Millisecond},
+		{"microsecond", time.Microsecond},
+		{"nanosecond", time.Nanosecond},
+	} {
+		add(int64(d/u.d), u.unit)
+		d %= u.d
+	}
+
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// String returns the bound in the grammar: "yesterday", "last june", "2 days AGO", "now-7d/d"
+func (b boundRelativeToNow) String() string {
+	if b.timeOfDay != "" {
+		day := b
+		day.timeOfDay = ""
+		return day.String() + " at " + strings.TrimSuffix(b.timeOfDay, ":00")
+	}
+
+	switch {
+	case b.isDateMath():
+		ops, _ := b.dateMathOps()
+		return dateMath{ops: ops, round: b.round}.String()
+	case b.anchor != nil:
+		if b.inFuture {
+			return b.duration.String() + " AFTER " + b.anchor.String()
+		}
+		return b.duration.String() + " BEFORE " + b.anchor.String()
+	case b.verbal == "":
+		if b.inFuture {
+			return b.duration.String() + " LATER"
+		}
+		return b.duration.String() + " AGO"
+	case isShortWord(b.verbal):
+		return b.verbal
+	case b.this:
+		return "this " + b.verbal
+	case b.year != 0:
+		return b.numberedString()
+	case b.count != 0:
+		return b.unitsString()
+	case b.inFuture:
+		return "next " + b.verbal
+	default:
+		return "last " + b.verbal
+	}
+}
+
+// String returns the anchor in the grammar: "last monday", "2022-04-01T00:00:00Z"
+func (a boundAnchor) String() string {
+	if a.abs != nil {
+		return formatAbs(*a.abs)
+	}
+	return a.relN.String()
+}
+
+// numberedString returns a numbered period in ISO 8601 like notation: "2022-W23", "2022-Q1", "fiscal 2023-H2"
+func (b boundRelativeToNow) numberedString() string {
+	var text string
+	if b.fiscal {
+		text = "fiscal "
+	}
+	if b.verbal == "week" {
+		return text + fmt.Sprintf("%04d-W%02d", b.year, b.index)
+	}
+	return text + fmt.Sprintf("%04d-%s%d", b.year, strings.ToUpper(b.verbal[:1]), b.index)
+}
+
+// unitsString returns a bound of several units: "last 3 weeks", "next 1 full month"
+func (b boundRelativeToNow) unitsString() string {
+	text := "last "
+	if b.inFuture {
+		text = "next "
+	}
+	text += strconv.Itoa(b.count) + " "
+	if b.full {
+		text += "full "
+	}
+	if b.count == 1 {
+		return text + b.verbal
+	}
+	if b.verbal == "half" {
+		return text + "halves"
+	}
+	return text + b.verbal + "s"
+}
+
+func isShortWord(word string) bool {
+	for _, w := range getShortWords() {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAbs returns an absolute bound in the canonical form: RFC 3339 with nanoseconds and the zone offset
+func formatAbs(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// String returns the specification in the canonical form of the grammar:
+// "FROM 2022-04-01T00:00:00Z WITHIN 1 day", "[FROM last week TO today) BY 1 hour".
+// Start(s.String()) returns a specification equal to s, see Equal.
+func (s Specification) String() string {
+	if s.sessionGap != nil {
+		return "SESSION GAP " + s.sessionGap.String()
+	}
+
+	var parts []string
+	switch {
+	case s.toDateWord() != "":
+		parts = append(parts, strings.ToUpper(s.toDateWord()))
+	case s.leftBoundAbs != nil:
+		parts = append(parts, "FROM "+formatAbs(*s.leftBoundAbs))
+	case s.leftBoundRel != nil:
+		parts = append(parts, "WITHIN "+s.leftBoundRel.String())
+	case s.leftBoundRelN != nil:
+		parts = append(parts, "FROM "+s.leftBoundRelN.String())
+	}
+	switch {
+	case s.toDateWord() != "":
+	case s.rightBoundAbs != nil:
+		parts = append(parts, "TO "+formatAbs(*s.rightBoundAbs))
+	case s.rightBoundRel != nil:
+		parts = append(parts, "WITHIN "+s.rightBoundRel.String())
+	case s.rightBoundRelN != nil:
+		parts = append(parts, "TO "+s.rightBoundRelN.String())
+	}
+	text := strings.Join(parts, " ")
+
+	if s.leftExcluded || s.rightExcluded {
+		left, right := "[", "]"
+		if s.leftExcluded {
+			left = "("
+		}
+		if s.rightExcluded {
+			right = ")"
+		}
+		text = left + text + right
+	}
+
+	if s.step != nil {
+		text += " BY " + s.step.String()
+	}
+	if s.location != nil {
+		text += " IN " + s.location.String()
+	}
+	return text
+}
+
+// toDateWord returns the period-to-date shortcut of the specification: "MTD" is "FROM now/M TO now",
+// an empty string if the specification is not a period to date
+func (s Specification) toDateWord() string {
+	if s.leftBoundRelN == nil || s.rightBoundRelN == nil || *s.rightBoundRelN != (boundRelativeToNow{verbal: "now"}) ||
+		*s.leftBoundRelN != *makeDateMathBound(nil, s.leftBoundRelN.round) {
+		return ""
+	}
+	for w, unit := range getToDateWords() {
+		if len(w) == 3 && unit == s.leftBoundRelN.round {
+			return w
+		}
+	}
+	return ""
+}
+
+// Equal returns true if both specifications resolve to equal windows at any time.
+// Absolute bounds are compared as time instants regardless of their locations.
+func (s Specification) Equal(o Specification) bool {
+	return equalTime(s.leftBoundAbs, o.leftBoundAbs) && equalTime(s.rightBoundAbs, o.rightBoundAbs) &&
+		equalPtr(s.leftBoundRel, o.leftBoundRel) && equalPtr(s.rightBoundRel, o.rightBoundRel) &&
+		s.leftBoundRelN.equal(o.leftBoundRelN) && s.rightBoundRelN.equal(o.rightBoundRelN) &&
+		s.leftExcluded == o.leftExcluded && s.rightExcluded == o.rightExcluded &&
+		equalPtr(s.step, o.step) && equalPtr(s.sessionGap, o.sessionGap) && equalLocation(s.location, o.location)
+}
+
+// equal compares bounds by value, anchors are compared recursively
+func (b *boundRelativeToNow) equal(o *boundRelativeToNow) bool {
+	if b == nil || o == nil {
+		return b == o
+	}
+	flat, otherFlat := *b, *o
+	flat.anchor, otherFlat.anchor = nil, nil
+	return flat == otherFlat && b.anchor.equal(o.anchor)
+}
+
+func (a *boundAnchor) equal(o *boundAnchor) bool {
+	if a == nil || o == nil {
+		return a == o
+	}
+	return equalTime(a.abs, o.abs) && a.relN.equal(o.relN)
+}
+
+func equalLocation(a, b *time.Location) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.String() == b.String()
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
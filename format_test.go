@@ -0,0 +1,135 @@
+package window
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSpecification_String(t *testing.T) {
+	// zone-less absolute bounds are parsed in the local zone
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	type test struct {
+		text, canonical string
+	}
+	tests := []test{
+		// 1. Abs-Abs
+		{"FROM 1 January 1991 TO 31 December 1991", "FROM 1991-01-01T00:00:00Z TO 1991-12-31T00:00:00Z"},
+		{"2022-04-01T10:00:00.5+03:30 to 2022-04-02T10:00:00-04:00", "FROM 2022-04-01T10:00:00.5+03:30 TO 2022-04-02T10:00:00-04:00"},
+		// 2. Abs-Rel
+		{"FROM May 8, 2009 5:57:51 PM WITHIN 365 days", "FROM 2009-05-08T17:57:51Z WITHIN 365 days"},
+		{"1 April 2022 within 1 day", "FROM 2022-04-01T00:00:00Z WITHIN 1 day"},
+		// 3. Abs-RelN
+		{"FROM Mon Jan 02 15:04:05 -0700 2006 TO now", "FROM 2006-01-02T15:04:05-07:00 TO now"},
+		{"FROM oct 7, 1970 UNTIL last week", "FROM 1970-10-07T00:00:00Z TO last week"},
+		// 4. Rel-Abs
+		{"1 day TO 1332151919", "WITHIN 1 day TO 2012-03-19T10:11:59Z"},
+		{"WITHIN 365 days BEFORE 12 Feb 2006 19:17", "FROM 365 days AGO TO 2006-02-12T19:17:00Z"},
+		// 5. Rel-Rel
+		{"WITHIN 60 DAYS", "WITHIN 60 days"},
+		{"1 week", "WITHIN 7 days"},
+		{"1 year and 1 quarter and 1 hour and 90 minutes and 1001 milliseconds", "WITHIN 1 year and 3 months and 2 hours and 30 minutes and 1 second and 1 millisecond"},
+		// 6. Rel-RelN
+		{"WITHIN 7 days UNTIL yesterday", "WITHIN 7 days TO yesterday"},
+		{"1 second and 3 nanoseconds UNTIL today", "WITHIN 1 second and 3 nanoseconds TO today"},
+		// 7. RelN-Abs
+		{"FROM 7 years ago TO 2014-12-16 06:20:00 UTC", "FROM 7 years AGO TO 2014-12-16T06:20:00Z"},
+		// 8. RelN-Rel
+		{"yesterday within 30 days", "FROM yesterday WITHIN 30 days"},
+		{"next year within 3 days and 2 hours", "FROM next year WITHIN 3 days and 2 hours"},
+		// 9. RelN-RelN
+		{"FROM 7 years ago UNTIL last week", "FROM 7 years AGO TO last week"},
+		{"2 days ago TO next week", "FROM 2 days AGO TO next week"},
+		{"FROM next week TO 7 days LATER", "FROM next week TO 7 days LATER"},
+		{"from last monday to next june", "FROM last monday TO next june"},
+		// inclusivity, step and sessions
+		{"[from yesterday to today)", "[FROM yesterday TO today)"},
+		{"(1 April 2022 within 1 day]", "(FROM 2022-04-01T00:00:00Z WITHIN 1 day]"},
+		{"from last week to today every 1 hour", "FROM last week TO today BY 1 hour"},
+		{"[30 minutes) by 5 minutes", "[WITHIN 30 minutes) BY 5 minutes"},
+		{"session gap 30 minutes", "SESSION GAP 30 minutes"},
+		// several units
+		{"from last 7d to next 2 full months", "FROM last 7 days TO next 2 full months"},
+		{"last full week to now", "FROM last 1 full week TO now"},
+		{"last 1.5 hours", "FROM last 90 minutes"},
+		{"from next 2.5 days to next 1.5 years", "FROM next 60 hours TO next 18 months"},
+		// current periods
+		{"this quarter to now", "FROM this quarter TO now"},
+		{"mtd", "MTD"},
+		{"[quarter to date) by 1 week", "[QTD) BY 7 days"},
+		{"from now/w to now", "WTD"},
+		// anchors
+		{"from 3 days before last monday to 2 hours after tomorrow", "FROM 3 days BEFORE last monday TO 2 hours AFTER tomorrow"},
+		{"from 1 hour after 2 days before 2022-04-01T00:00:00Z to now", "FROM 1 hour AFTER 2 days BEFORE 2022-04-01T00:00:00Z TO now"},
+		{"from 1 day after now/d to 1 week before fiscal Q1 2023", "FROM 1 day AFTER now/d TO 7 days BEFORE fiscal 2023-Q1"},
+		{"from 3 days before to 2 days after", "FROM 3 days AGO TO 2 days LATER"},
+		// time of day
+		{"from yesterday 22:00 to today 6am", "FROM yesterday at 22:00 TO today at 06:00"},
+		{"from last monday at noon to next day at 9:30:15 PM", "FROM last monday at 12:00 TO next day at 21:30:15"},
+		{"[from yesterday start of day to today end of day)", "[FROM yesterday at 00:00 TO today at end of day)"},
+		// time zones
+		{"from yesterday to today in Europe/Berlin", "FROM yesterday TO today IN Europe/Berlin"},
+		{"2022-04-01 to 2022-04-02 TZ=America/Denver", "FROM 2022-04-01T00:00:00-06:00 TO 2022-04-02T00:00:00-06:00 IN America/Denver"},
+		{"[from 2022-04-01T10:00:00Z to now) by 1 hour in Asia/Tokyo", "[FROM 2022-04-01T10:00:00Z TO now) BY 1 hour IN Asia/Tokyo"},
+		{"mtd in UTC", "MTD IN UTC"},
+		// numbered weeks
+		{"from week 23 of 2022 to 2022w25", "FROM 2022-W23 TO 2022-W25"},
+		{"from q1 2022 to h2 2022", "FROM 2022-Q1 TO 2022-H2"},
+		{"from fiscal Q1 2023 to fiscal 2023h2", "FROM fiscal 2023-Q1 TO fiscal 2023-H2"},
+		{"from last quarter to next 2 full halves", "FROM last quarter TO next 2 full halves"},
+		// date math
+		{"from now-7d/d to now/d", "FROM now-7d/d TO now/d"},
+		{"from now-1M+3d to now-2H", "FROM now-1M+3d TO 2 hours AGO"},
+		{"from now-1y-6M/d to now+1h-1d", "FROM now-1y-6M/d TO 1 day BEFORE 1 hour LATER"},
+		{"from now-1y-6M to now", "FROM 6 months BEFORE 1 year AGO TO now"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := spec.String(); s != tt.canonical {
+				t.Errorf("canonical form [%s] should be [%s]", s, tt.canonical)
+			}
+
+			// round-trip
+			parsed, err := Start(spec.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.Equal(spec) {
+				t.Errorf("parsed specification [%s] should be equal to [%s]", parsed, spec)
+			}
+			if parsed.String() != spec.String() {
+				t.Errorf("canonical form is not stable: [%s] and [%s]", parsed, spec)
+			}
+		})
+	}
+}
+
+func TestPeriod_String(t *testing.T) {
+	type test struct {
+		period Period
+		text   string
+	}
+	tests := []test{
+		{Period{}, "0 seconds"},
+		{Period{Days: 1}, "1 day"},
+		{Period{Years: 2, Months: 1}, "2 years and 1 month"},
+		{Period{Duration: 25*time.Hour + time.Nanosecond}, "25 hours and 1 nanosecond"},
+		{Period{Duration: 1500 * time.Microsecond}, "1 millisecond and 500 microseconds"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			if s := tt.period.String(); s != tt.text {
+				t.Errorf("period [%s] should be [%s]", s, tt.text)
+			}
+		})
+	}
+}
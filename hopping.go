@@ -0,0 +1,76 @@
+package window
+
+import (
+	"fmt"
+	"time"
+)
+
+// Hopping generates windows of a fixed size which start every hop: "30 minutes every 5 minutes".
+// Windows start at alignment+k*hop for any integer k and are half-open [start, start+size), so an event belongs to
+// size/hop windows. If the hop is longer than the size there are gaps between windows.
+type Hopping struct {
+	size, hop Period
+	alignment time.Time
+}
+
+// NewHopping makes a generator of hopping windows, size and hop must be positive
+func NewHopping(size, hop Period, alignment time.Time) (*Hopping, error) {
+	if !isPositive(size) || !isPositive(hop) {
+		return nil, ErrInvalidStep
+	}
+	return &Hopping{size: size, hop: hop, alignment: alignment}, nil
+}
+
+// Hopping makes a generator of hopping windows from a sliding specification with a step: "30 minutes every 5 minutes".
+// The slide is the size of windows and the step is the hop between them.
+func (s *Specification) Hopping(alignment time.Time) (*Hopping, error) {
+	if s.leftBoundRel == nil || s.rightBoundAbs != nil || s.rightBoundRel != nil || s.rightBoundRelN != nil {
+		return nil, ErrNotSliding
+	}
+	if s.step == nil {
+		return nil, fmt.Errorf("%w: the hop is not specified", ErrInvalidStep)
+	}
+	return NewHopping(*s.leftBoundRel, *s.step, alignment)
+}
+
+// window returns the k-th window
+func (h *Hopping) window(k int) *Window {
+	start := h.hop.Times(k).AddTo(h.alignment)
+	return windowFromEdges(edge{start, false}, edge{h.size.AddTo(start), true})
+}
+
+// Windows returns all windows which overlap the given range ordered by start
+func (h *Hopping) Windows(outer *Window) ([]*Window, error) {
+	from, to, err := outer.edges()
+	if err != nil {
+		return nil, err
+	}
+
+	// step back to the earliest window which overlaps the range start
+	k := stepIndex(h.alignment, h.hop, from.t)
+	for h.window(k - 1).to.After(from.t) {
+		k--
+	}
+
+	var windows []*Window
+	for w := h.window(k); !endsBefore(to, edge{*w.from, false}); w = h.window(k) {
+		if w.Overlaps(outer) {
+			windows = append(windows, w)
+		}
+		k++
+	}
+	return windows, nil
+}
+
+// AssignWindows returns all windows which contain the time point ordered by start
+func (h *Hopping) AssignWindows(t time.Time) []*Window {
+	var windows []*Window
+	for k := stepIndex(h.alignment, h.hop, t); ; k-- {
+		w := h.window(k)
+		if !w.Contains(t) {
+			break
+		}
+		windows = append([]*Window{w}, windows...)
+	}
+	return windows
+}
@@ -0,0 +1,136 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func windowStarts(windows []*Window, layout string) string {
+	starts := make([]string, 0, len(windows))
+	for _, w := range windows {
+		starts = append(starts, w.from.Format(layout))
+	}
+	return strings.Join(starts, " ")
+}
+
+func TestHopping_AssignWindows(t *testing.T) {
+	epoch := time.Unix(0, 0).UTC()
+
+	type test struct {
+		text   string
+		point  string
+		starts string
+	}
+	tests := []test{
+		{"30 minutes every 10 minutes", "1 May 2022 10:05", "09:40 09:50 10:00"},
+		{"30 minutes every 10 minutes", "1 May 2022 10:00", "09:40 09:50 10:00"},
+		{"within 10 minutes by 30 minutes", "1 May 2022 10:05", "10:00"},
+		{"within 10 minutes by 30 minutes", "1 May 2022 10:15", ""},
+		{"1 hour every 1 hour", "1 May 2022 10:15", "10:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := spec.Hopping(epoch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			windows := h.AssignWindows(dateparse.MustParse(tt.point))
+			if r := windowStarts(windows, "15:04"); r != tt.starts {
+				t.Errorf("windows [%s] should be [%s]", r, tt.starts)
+			}
+			for _, w := range windows {
+				if w.Duration() != h.size.Approximate() || w.IncludesTo() {
+					t.Errorf("unexpected window %v", w)
+				}
+			}
+		})
+	}
+}
+
+func TestHopping_CalendarHop(t *testing.T) {
+	h, err := NewHopping(Period{Days: 7}, Period{Days: 1}, dateparse.MustParse("1 Jan 2022"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows := h.AssignWindows(dateparse.MustParse("3 May 2022 12:00"))
+	if r := windowStarts(windows, "2 Jan"); r != "27 Apr 28 Apr 29 Apr 30 Apr 1 May 2 May 3 May" {
+		t.Errorf("unexpected windows [%s]", r)
+	}
+
+	h, _ = NewHopping(Period{Months: 3}, Period{Months: 1}, dateparse.MustParse("1 Jan 2022"))
+	windows = h.AssignWindows(dateparse.MustParse("3 May 2030 12:00"))
+	if r := windowStarts(windows, "2 Jan 2006"); r != "1 Mar 2030 1 Apr 2030 1 May 2030" {
+		t.Errorf("unexpected windows [%s]", r)
+	}
+}
+
+func TestHopping_Windows(t *testing.T) {
+	h, err := NewHopping(Period{Duration: 30 * time.Minute}, Period{Duration: 10 * time.Minute}, time.Unix(0, 0).UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type test struct {
+		outer  string
+		starts string
+	}
+	tests := []test{
+		{"[1 May 2022 10:00 to 1 May 2022 10:20)", "09:40 09:50 10:00 10:10"},
+		{"[1 May 2022 10:00 to 1 May 2022 10:20]", "09:40 09:50 10:00 10:10 10:20"},
+		{"(1 May 2022 10:00 to 1 May 2022 10:05)", "09:40 09:50 10:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			windows, err := h.Windows(resolveWindow(t, tt.outer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := windowStarts(windows, "15:04"); r != tt.starts {
+				t.Errorf("windows [%s] should be [%s]", r, tt.starts)
+			}
+		})
+	}
+
+	if _, err = h.Windows(resolveWindow(t, "30 days")); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+}
+
+func TestHopping_Errors(t *testing.T) {
+	type test struct {
+		text string
+		err  error
+	}
+	tests := []test{
+		{"yesterday to today", ErrNotSliding},
+		{"1 Apr 2022 within 1 day by 1 hour", ErrNotSliding},
+		{"30 minutes", ErrInvalidStep},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = spec.Hopping(time.Time{}); !errors.Is(err, tt.err) {
+				t.Errorf("error [%v] should be [%v]", err, tt.err)
+			}
+		})
+	}
+
+	if _, err := NewHopping(Period{Duration: time.Minute}, Period{}, time.Time{}); !errors.Is(err, ErrInvalidStep) {
+		t.Errorf("error [%v] should be [%v]", err, ErrInvalidStep)
+	}
+}
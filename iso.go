@@ -0,0 +1,288 @@
+package window
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+var isoDurationRE = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// isoBasicLayouts are ISO 8601 basic format layouts which dateparse does not recognize: "20220101T000000Z"
+var isoBasicLayouts = []string{"20060102T150405Z0700", "20060102T1504Z0700", "20060102T150405", "20060102T1504", "20060102"}
+
+// parseISODuration parses an ISO 8601 duration: "P1Y2M10DT2H30M", "P2W", "PT0.5S".
+// Years, months, weeks and days become calendar components of the period, a fraction is allowed in hours, minutes
+// and seconds.
+func parseISODuration(text string) (p Period, err error) {
+	upper := strings.ToUpper(text)
+	m := isoDurationRE.FindStringSubmatch(upper)
+	if m == nil || upper == "P" || strings.HasSuffix(upper, "T") { // at least one component is required
+		return p, fmt.Errorf("invalid ISO 8601 duration %q", text)
+	}
+
+	atoi := func(s string) int {
+		if s == "" {
+			return 0
+		}
+		n, atoiErr := strconv.Atoi(s)
+		if atoiErr != nil && err == nil {
+			err = fmt.Errorf("ISO 8601 duration %q is too long", text)
+		}
+		return n
+	}
+	p.Years = atoi(m[1])
+	p.Months = atoi(m[2])
+	weeks, days := atoi(m[3]), atoi(m[4])
+	if err == nil && weeks > (math.MaxInt-days)/7 {
+		err = fmt.Errorf("ISO 8601 duration %q is too long", text)
+	}
+	if err != nil {
+		return Period{}, err
+	}
+	p.Days = weeks*7 + days
+	for i, unit := range []string{"h", "m", "s"} {
+		if m[5+i] == "" {
+			continue
+		}
+		d, durationErr := time.ParseDuration(strings.Replace(m[5+i], ",", ".", 1) + unit)
+		if durationErr != nil {
+			return Period{}, durationErr
+		}
+		p.Duration += d
+	}
+	return p, nil
+}
+
+// parseISOTime parses an ISO 8601 date-time in the extended ("2022-04-01T00:00:00Z") or the basic ("20220401T000000Z")
+// format. Zone-less time is parsed in the local zone.
+func parseISOTime(text string) (time.Time, error) {
+	t, err := dateparse.ParseIn(text, time.Local)
+	if err == nil {
+		return t, nil
+	}
+	for _, layout := range isoBasicLayouts {
+		if basic, basicErr := time.ParseInLocation(layout, text, time.Local); basicErr == nil {
+			return basic, nil
+		}
+	}
+	return t, err
+}
+
+// StartISO makes a specification of an ISO 8601 time interval in any of four forms:
+//
+//	2022-04-01T00:00:00Z/2022-04-02T00:00:00Z  start and end: FROM x TO y
+//	2022-04-01T00:00:00Z/P1D                   start and duration: FROM x WITHIN 1 day
+//	P1Y2M10DT2H30M/2022-06-01                  duration and end: WITHIN 1 year and 2 months... TO y
+//	P1D                                        duration only, a sliding window: WITHIN 1 day
+//
+// ISO 8601 intervals are half-open, so is the specification: [start, end).
+func StartISO(text string) (s Specification, err error) {
+	parts := strings.Split(text, "/")
+	if len(parts) > 2 || strings.HasPrefix(strings.ToUpper(text), "R") {
+		return s, newParseError(text, 0, "repeating intervals are parsed by StartISORecurrence", "date", "duration")
+	}
+
+	offset := 0
+	for i, part := range parts {
+		side := SideLeft
+		if i == 1 {
+			side = SideRight
+		}
+		abs, rel, partErr := parseISOPart(part)
+		if partErr != nil {
+			e := newParseError(text, offset, partErr.Error(), "date", "duration")
+			e.Side = side
+			return Specification{}, e
+		}
+		if side == SideLeft {
+			s.leftBoundAbs, s.leftBoundRel = abs, rel
+		} else {
+			s.rightBoundAbs, s.rightBoundRel = abs, rel
+		}
+		offset += len(part) + 1
+	}
+	if s.leftBoundAbs == nil && s.rightBoundAbs == nil && len(parts) > 1 {
+		return Specification{}, ErrTwoRelBounds
+	}
+	if len(parts) == 1 && s.leftBoundAbs != nil {
+		e := newParseError(text, len(text), "", "/")
+		e.Side = SideRight
+		return Specification{}, e
+	}
+
+	s.rightExcluded = true
+	err = s.validate()
+	return
+}
+
+// parseISOPart parses a part of an interval: either a time or a duration
+func parseISOPart(part string) (abs *time.Time, rel *Period, err error) {
+	if strings.HasPrefix(strings.ToUpper(part), "P") {
+		p, durationErr := parseISODuration(part)
+		return nil, &p, durationErr
+	}
+	t, timeErr := parseISOTime(part)
+	return &t, nil, timeErr
+}
+
+// Recurrence is an ISO 8601 repeating interval: "R5/2022-01-01T00:00:00Z/PT1H" is 5 hourly windows
+type Recurrence struct {
+	Repetitions int           // the number of windows, -1 if unbounded: "R/2022-01-01T00:00:00Z/PT1H"
+	Interval    Specification // the first window, see StartISO
+}
+
+// StartISORecurrence parses an ISO 8601 repeating interval "Rn/interval", the interval is parsed with StartISO
+func StartISORecurrence(text string) (r Recurrence, err error) {
+	head, interval, found := strings.Cut(text, "/")
+	if !found || !strings.HasPrefix(strings.ToUpper(head), "R") {
+		return r, newParseError(text, 0, "", "R")
+	}
+
+	r.Repetitions = -1
+	if head[1:] != "" {
+		if r.Repetitions, err = strconv.Atoi(head[1:]); err != nil || r.Repetitions < 0 {
+			return Recurrence{}, newParseError(text, 1, "", "number", "/")
+		}
+	}
+
+	if r.Interval, err = StartISO(interval); err != nil {
+		if parseErr, ok := err.(*ParseError); ok {
+			e := newParseError(text, parseErr.Offset+len(head)+1, parseErr.Reason, parseErr.Expected...)
+			e.Side = parseErr.Side
+			err = e
+		}
+		return Recurrence{}, err
+	}
+	return
+}
+
+// Windows returns up to n windows of the recurrence in the order of repetition. Intervals given by the end and the
+// duration repeat back in time. It returns ErrSlidingHasNoBounds if the interval has only a duration and
+// ErrNegativeCount if n is negative.
+func (r Recurrence) Windows(n int) ([]*Window, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+	first, err := r.Interval.TryResolveAt(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := first.TryGetBounds()
+	if err != nil {
+		return nil, err
+	}
+
+	// window edges are anchor+k*step, so calendar steps do not drift: P1M from 31 January ends on 28 February, 31 March...
+	anchor, step, backward := from, calendarStep(from, to), false
+	switch {
+	case r.Interval.rightBoundRel != nil:
+		step = *r.Interval.rightBoundRel
+	case r.Interval.leftBoundRel != nil:
+		anchor, step, backward = to, r.Interval.leftBoundRel.Neg(), true
+	}
+
+	if r.Repetitions >= 0 && r.Repetitions < n {
+		n = r.Repetitions
+	}
+	windows := make([]*Window, 0, n)
+	for k := 0; k < n; k++ {
+		w := *first
+		left, right := step.Times(k).AddTo(anchor), step.Times(k+1).AddTo(anchor)
+		if backward {
+			left, right = right, left
+		}
+		w.from, w.to = &left, &right
+		windows = append(windows, &w)
+	}
+	return windows, nil
+}
+
+// calendarStep returns the period from one time to another as whole days on the wall clock in the location of from
+// and the time left after them, so windows given by their start and end repeat at the same clock time across DST
+// transitions
+func calendarStep(from, to time.Time) Period {
+	to = to.In(from.Location())
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	days := int(time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).Sub(time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)) /
+		(24 * time.Hour))
+	if days > 0 && from.AddDate(0, 0, days).After(to) {
+		days--
+	}
+	return Period{Days: days, Duration: to.Sub(from.AddDate(0, 0, days))}
+}
+
+// String returns the recurrence in ISO 8601: "R5/2022-01-01T00:00:00Z/PT1H"
+func (r Recurrence) String() string {
+	repetitions := ""
+	if r.Repetitions >= 0 {
+		repetitions = strconv.Itoa(r.Repetitions)
+	}
+	interval, _ := r.Interval.ISO()
+	return "R" + repetitions + "/" + interval
+}
+
+// ISO returns the period as an ISO 8601 duration: "P1Y2M10DT2H30M", "PT0.5S".
+// Negative components are not defined by ISO 8601 and printed with a minus sign.
+func (p Period) ISO() string {
+	text := "P"
+	add := func(n int64, designator string) {
+		if n != 0 {
+			text += fmt.Sprintf("%d%s", n, designator)
+		}
+	}
+	add(int64(p.Years), "Y")
+	add(int64(p.Months), "M")
+	add(int64(p.Days), "D")
+
+	if p.Duration != 0 || p.IsZero() {
+		text += "T"
+		add(int64(p.Duration/time.Hour), "H")
+		add(int64(p.Duration%time.Hour/time.Minute), "M")
+		if seconds := p.Duration % time.Minute; seconds != 0 || p.IsZero() {
+			text += strconv.FormatFloat(seconds.Seconds(), 'f', -1, 64) + "S"
+		}
+	}
+	return text
+}
+
+// ISO returns the specification as an ISO 8601 interval, see StartISO. Only half-open [start, end) specifications of
+// absolute bounds and bounds relative to each other can be expressed, ErrNoISO is returned otherwise. A time zone
+// clause can't be expressed either.
+func (s Specification) ISO() (string, error) {
+	if s.sessionGap != nil || s.step != nil || s.leftBoundRelN != nil || s.rightBoundRelN != nil {
+		return "", ErrNoISO
+	}
+	if s.leftExcluded || !s.rightExcluded || s.location != nil {
+		return "", ErrNoISO
+	}
+	var parts []string
+	for _, b := range []struct {
+		abs *time.Time
+		rel *Period
+	}{{s.leftBoundAbs, s.leftBoundRel}, {s.rightBoundAbs, s.rightBoundRel}} {
+		switch {
+		case b.abs != nil:
+			parts = append(parts, formatAbs(*b.abs))
+		case b.rel != nil:
+			parts = append(parts, b.rel.ISO())
+		}
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// ISO returns the window as an ISO 8601 interval of its bounds "2022-04-01T00:00:00Z/2022-04-02T00:00:00Z" or as
+// a duration for sliding windows "P30D". ISO 8601 does not define inclusivity of bounds, so it is lost: windows
+// "[a, b]" and "(a, b)" are printed the same as "[a, b)", which is what StartISO parses the interval back to.
+func (w Window) ISO() string {
+	if w.from == nil || w.to == nil {
+		return w.slide.ISO()
+	}
+	return formatAbs(*w.from) + "/" + formatAbs(*w.to)
+}
@@ -0,0 +1,195 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStartISO(t *testing.T) {
+	// zone-less absolute bounds are parsed in the local zone
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	type test struct {
+		text, grammar string
+	}
+	tests := []test{
+		{"2022-04-01T00:00:00Z/2022-04-02T00:00:00Z", "[from 2022-04-01T00:00:00Z to 2022-04-02T00:00:00Z)"},
+		{"2022-04-01T00:00:00Z/P1D", "[from 2022-04-01T00:00:00Z within 1 day)"},
+		{"P1Y2M10DT2H30M/2022-06-01", "[within 1 year and 2 months and 10 days and 2 hours and 30 minutes to 2022-06-01)"},
+		{"P2W", "[within 14 days)"},
+		{"PT1.5H", "[within 90 minutes)"},
+		{"pt0,5s", "[within 500 milliseconds)"},
+		{"20220401T103000Z/PT1H", "[from 2022-04-01T10:30:00Z within 1 hour)"},
+		{"2022-04-01T10:00+03:00/20220402", "[from 2022-04-01T10:00:00+03:00 to 2022-04-02)"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := StartISO(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := Start(tt.grammar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !spec.Equal(expected) {
+				t.Errorf("specification [%s] should be [%s]", spec, expected)
+			}
+
+			// print back
+			iso, err := spec.ISO()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := StartISO(iso)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.Equal(spec) {
+				t.Errorf("ISO form [%s] should be parsed as [%s]", iso, spec)
+			}
+		})
+	}
+}
+
+func TestStartISO_Fail(t *testing.T) {
+	type test struct {
+		text   string
+		side   BoundSide
+		offset int
+	}
+	tests := []test{
+		{"P1D/yesterday", SideRight, 4},
+		{"P1DT", SideLeft, 0},
+		{"P", SideLeft, 0},
+		{"2022-04-01", SideRight, 10},
+		{"R5/2022-04-01/P1D", SideLeft, 0},
+		{"2022-04-01/P1.5D", SideRight, 11},
+		{"P99999999999999999999Y", SideLeft, 0},
+		{"P1999999999999999999W", SideLeft, 0},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			_, err := StartISO(tt.text)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error [%v] should be a ParseError", err)
+			}
+			if parseErr.Side != tt.side || parseErr.Offset != tt.offset {
+				t.Errorf("error [%v] should be at the %s bound at %d", err, tt.side, tt.offset)
+			}
+		})
+	}
+
+	if _, err := StartISO("P1D/PT1H"); !errors.Is(err, ErrTwoRelBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrTwoRelBounds)
+	}
+}
+
+func TestRecurrence_Windows(t *testing.T) {
+	type test struct {
+		text     string
+		n        int
+		expected string
+	}
+	tests := []test{
+		{"R3/2022-01-01T00:00:00Z/PT1H", 10, "[2022-01-01 00:00,2022-01-01 01:00) [2022-01-01 01:00,2022-01-01 02:00) [2022-01-01 02:00,2022-01-01 03:00)"},
+		{"R/2022-01-31T00:00:00Z/P1M", 3, "[2022-01-31 00:00,2022-02-28 00:00) [2022-02-28 00:00,2022-03-31 00:00) [2022-03-31 00:00,2022-04-30 00:00)"},
+		{"R2/P1D/2022-01-03T00:00:00Z", 10, "[2022-01-02 00:00,2022-01-03 00:00) [2022-01-01 00:00,2022-01-02 00:00)"},
+		{"R2/2022-01-01T00:00:00Z/2022-01-01T00:30:00Z", 10, "[2022-01-01 00:00,2022-01-01 00:30) [2022-01-01 00:30,2022-01-01 01:00)"},
+		{"R0/2022-01-01T00:00:00Z/PT1H", 10, ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			r, err := StartISORecurrence(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			windows, err := r.Windows(tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := bucketsToString(windows, "2006-01-02 15:04"); s != tt.expected {
+				t.Errorf("windows [%s] should be [%s]", s, tt.expected)
+			}
+			if r.String() != tt.text {
+				t.Errorf("recurrence [%s] should be printed as [%s]", r, tt.text)
+			}
+		})
+	}
+
+	r, _ := StartISORecurrence("R5/PT1H")
+	if _, err := r.Windows(1); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+	r, _ = StartISORecurrence("R5/2022-01-01T00:00:00Z/PT1H")
+	if _, err := r.Windows(-1); !errors.Is(err, ErrNegativeCount) {
+		t.Errorf("error [%v] should be [%v]", err, ErrNegativeCount)
+	}
+	if _, err := StartISORecurrence("R5/P1D/2022-01-0x"); err == nil {
+		t.Errorf("invalid interval should not be accepted")
+	}
+}
+
+func TestWindow_ISO(t *testing.T) {
+	spec, _ := Start("[from 2022-04-01T00:00:00+03:00 within 1 day)")
+	w := spec.ResolveAt(time.Now())
+	if s := w.ISO(); s != "2022-04-01T00:00:00+03:00/2022-04-02T00:00:00+03:00" {
+		t.Errorf("unexpected ISO form %s", s)
+	}
+
+	spec, _ = Start("within 1 year and 10 days and 2 hours and 1 second and 500 milliseconds")
+	if s := spec.ResolveAt(time.Now()).ISO(); s != "P1Y10DT2H1.5S" {
+		t.Errorf("unexpected ISO form %s", s)
+	}
+	if s := (Period{}).ISO(); s != "PT0S" {
+		t.Errorf("unexpected ISO form %s", s)
+	}
+
+	for _, text := range []string{
+		"from yesterday to today",
+		"from 2022-04-01 to 2022-04-02",
+		"(from 2022-04-01 to 2022-04-02)",
+		"[from 2022-04-01 to 2022-04-02) in Europe/Berlin",
+	} {
+		spec, _ = Start(text)
+		if _, err := spec.ISO(); !errors.Is(err, ErrNoISO) {
+			t.Errorf("%s: error [%v] should be [%v]", text, err, ErrNoISO)
+		}
+	}
+}
+
+func TestRecurrence_WindowsDST(t *testing.T) {
+	// 22 March 2022 00:00 +0330 clocks jumped to 01:00 +0430 in Tehran
+	tehran, err := time.LoadLocation("Asia/Tehran")
+	if err != nil {
+		t.Skip(err)
+	}
+	local := time.Local
+	time.Local = tehran
+	defer func() { time.Local = local }()
+
+	r, err := StartISORecurrence("R5/2022-03-20T00:00/2022-03-21T00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, err := r.Windows(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[2022-03-20 00:00,2022-03-21 00:00) [2022-03-21 00:00,2022-03-22 01:00) " +
+		"[2022-03-22 01:00,2022-03-23 00:00) [2022-03-23 00:00,2022-03-24 00:00) [2022-03-24 00:00,2022-03-25 00:00)"
+	if s := bucketsToString(windows, "2006-01-02 15:04"); s != expected {
+		t.Errorf("windows [%s] should be [%s]", s, expected)
+	}
+	if windows[2].Duration() != 23*time.Hour {
+		t.Errorf("window [%s] should last 23 hours", windows[2])
+	}
+}
@@ -0,0 +1,660 @@
+package window
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// binaryVersion is the first byte of the binary form, it changes when the layout changes.
+// Version 1 only has the direction, the verbal and the duration of bounds relative to now, it is still read.
+// Version 2 adds the rounding, other fields are written when their flag is set, so new fields come with new flags and
+// readers reject flags they don't know.
+const binaryVersion byte = 2
+
+var errBinaryFormat = errors.New("invalid binary form")
+
+// MarshalText returns the canonical form of the specification, see String
+func (s Specification) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText recognizes the specification in the text, see Start
+func (s *Specification) UnmarshalText(text []byte) error {
+	spec, err := Start(string(text))
+	if err != nil {
+		return err
+	}
+	*s = spec
+	return nil
+}
+
+type periodJSON struct {
+	Years    int    `json:"years,omitempty"`
+	Months   int    `json:"months,omitempty"`
+	Days     int    `json:"days,omitempty"`
+	Duration string `json:"duration,omitempty"` // time.Duration format: "2h30m"
+}
+
+// MarshalJSON returns the period as an object: {"months":1,"duration":"2h30m"}
+func (p Period) MarshalJSON() ([]byte, error) {
+	pj := periodJSON{Years: p.Years, Months: p.Months, Days: p.Days}
+	if p.Duration != 0 {
+		pj.Duration = p.Duration.String()
+	}
+	return json.Marshal(pj)
+}
+
+// UnmarshalJSON reads the period from an object, see MarshalJSON
+func (p *Period) UnmarshalJSON(data []byte) error {
+	var pj periodJSON
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+	*p = Period{Years: pj.Years, Months: pj.Months, Days: pj.Days}
+	if pj.Duration != "" {
+		d, err := time.ParseDuration(pj.Duration)
+		if err != nil {
+			return err
+		}
+		p.Duration = d
+	}
+	return nil
+}
+
+type boundJSON struct {
+	Kind   string     `json:"kind"`             // see BoundKind
+	Time   *time.Time `json:"time,omitempty"`   // absolute
+	Period *Period    `json:"period,omitempty"` // relative, relative to now: "2 days ago"
+	Verbal string     `json:"verbal,omitempty"` // relative to now: "yesterday", "last week"
+	Future bool       `json:"future,omitempty"` // relative to now: "next week", "2 days later"
+	Round  string     `json:"round,omitempty"`  // relative to now: "day" in "now-7d/d"
+	Count  int        `json:"count,omitempty"`  // relative to now: 3 in "last 3 weeks"
+	Full   bool       `json:"full,omitempty"`   // relative to now: "last 3 full weeks"
+	This   bool       `json:"this,omitempty"`   // relative to now: "this week"
+	Year   int        `json:"year,omitempty"`   // numbered period: 2022 in "2022-W23"
+	Index  int        `json:"index,omitempty"`  // numbered period: 23 in "2022-W23"
+	Fiscal bool       `json:"fiscal,omitempty"` // numbered period: "fiscal Q1 2023"
+	Anchor *boundJSON `json:"anchor,omitempty"` // relative to now: "last monday" in "3 days before last monday"
+	// relative to now: "09:00:00" in "yesterday at 9am" or "end of day"
+	TimeOfDay string `json:"time_of_day,omitempty"`
+}
+
+type specificationJSON struct {
+	Left          *boundJSON `json:"left,omitempty"`
+	Right         *boundJSON `json:"right,omitempty"`
+	LeftExcluded  bool       `json:"left_excluded,omitempty"`
+	RightExcluded bool       `json:"right_excluded,omitempty"`
+	Step          *Period    `json:"step,omitempty"`
+	SessionGap    *Period    `json:"session_gap,omitempty"`
+	Location      string     `json:"location,omitempty"` // IANA time zone name: "Europe/Berlin"
+}
+
+func makeBoundJSON(abs *time.Time, rel *Period, relN *boundRelativeToNow) *boundJSON {
+	switch {
+	case abs != nil:
+		return &boundJSON{Kind: KindAbsolute.String(), Time: abs}
+	case rel != nil:
+		return &boundJSON{Kind: KindRelativeToOther.String(), Period: rel}
+	case relN != nil:
+		b := &boundJSON{Kind: KindRelativeToNow.String(), Verbal: relN.verbal, Future: relN.inFuture, Round: relN.round,
+			Count: relN.count, Full: relN.full, This: relN.this, Year: relN.year, Index: relN.index, Fiscal: relN.fiscal,
+			TimeOfDay: relN.timeOfDay}
+		if relN.verbal == "" {
+			b.Period = &relN.duration
+		}
+		if relN.anchor != nil {
+			b.Anchor = makeBoundJSON(relN.anchor.abs, nil, relN.anchor.relN)
+		}
+		return b
+	}
+	return nil
+}
+
+func (b *boundJSON) read() (abs *time.Time, rel *Period, relN *boundRelativeToNow, err error) {
+	if b == nil {
+		return
+	}
+	switch b.Kind {
+	case KindAbsolute.String():
+		if b.Time == nil {
+			err = fmt.Errorf("absolute bound has no time")
+		}
+		abs = b.Time
+	case KindRelativeToOther.String():
+		if b.Period == nil {
+			err = fmt.Errorf("relative bound has no period")
+		}
+		rel = b.Period
+	case KindRelativeToNow.String():
+		relN = &boundRelativeToNow{inFuture: b.Future, verbal: b.Verbal, round: b.Round, count: b.Count, full: b.Full,
+			this: b.This, year: b.Year, index: b.Index, fiscal: b.Fiscal, timeOfDay: b.TimeOfDay}
+		if b.Period != nil {
+			relN.duration = *b.Period
+		}
+		if b.Anchor != nil {
+			anchor := &boundAnchor{}
+			var anchorRel *Period
+			if anchor.abs, anchorRel, anchor.relN, err = b.Anchor.read(); err != nil {
+				return
+			}
+			if anchorRel != nil {
+				err = fmt.Errorf("relative bound can't be an anchor")
+				return
+			}
+			relN.anchor = anchor
+		}
+		err = relN.validate()
+	default:
+		err = fmt.Errorf("unknown bound kind %q", b.Kind)
+	}
+	return
+}
+
+// validate checks that the bound can be resolved
+func (b *boundRelativeToNow) validate() error {
+	if b.anchor != nil {
+		if b.verbal != "" || b.count != 0 || b.this || b.year != 0 {
+			return fmt.Errorf("period [%s] can't have an anchor", b.verbal)
+		}
+		if err := b.anchor.validate(); err != nil {
+			return err
+		}
+		if _, ok := b.dateMathOps(); b.round != "" && !ok {
+			return fmt.Errorf("rounding of a point counted from [%s] not supported", b.anchor)
+		}
+	}
+	if b.round != "" && (b.verbal != "" || dateMathUnit(b.round) == "") {
+		return fmt.Errorf("rounding to [%s] not recognized", b.round)
+	}
+	if b.count < 0 || b.count == 0 && b.full || b.count != 0 && unitWord(b.verbal) != b.verbal {
+		return fmt.Errorf("%d units [%s] not recognized", b.count, b.verbal)
+	}
+	if _, err := mapUnitToPeriod(b.verbal).TryTimes(b.count); err != nil {
+		return err
+	}
+	if b.this && (b.count != 0 || b.inFuture || unitWord(b.verbal) != b.verbal) {
+		return fmt.Errorf("current period [%s] not recognized", b.verbal)
+	}
+	last, numbered := getNumberedPeriods()[b.verbal]
+	if b.year != 0 && (!numbered || b.index < 1 || b.index > last || b.fiscal && b.verbal == "week") ||
+		b.year == 0 && (b.index != 0 || b.fiscal) {
+		return fmt.Errorf("%s %d of %d not recognized", b.verbal, b.index, b.year)
+	}
+	if b.timeOfDay != "" {
+		if _, err := time.Parse(timeOfDayLayout, b.timeOfDay); err != nil && b.timeOfDay != endOfDay {
+			return fmt.Errorf("time of day [%s] not recognized", b.timeOfDay)
+		}
+		if !isDayPeriod(b.verbal) || b.count != 0 || b.this || b.year != 0 {
+			return fmt.Errorf("time of day of [%s] not recognized", b.verbal)
+		}
+	}
+	if b.verbal == "" || isShortWord(b.verbal) || b.count != 0 || b.this || b.year != 0 {
+		return nil
+	}
+	for _, w := range getPeriodWords() {
+		if w == b.verbal {
+			return nil
+		}
+	}
+	return fmt.Errorf("verbal [%s] not recognized", b.verbal)
+}
+
+// validate checks that the anchor has exactly one bound which can be resolved
+func (a *boundAnchor) validate() error {
+	if (a.abs == nil) == (a.relN == nil) {
+		return fmt.Errorf("anchor must be either absolute or relative to now")
+	}
+	if a.relN != nil {
+		return a.relN.validate()
+	}
+	return nil
+}
+
+// MarshalJSON returns the structured form of the specification:
+// {"left":{"kind":"relative_to_now","verbal":"week"},"right":{"kind":"relative","period":{"days":1}}}
+func (s Specification) MarshalJSON() ([]byte, error) {
+	return json.Marshal(specificationJSON{
+		Left:          makeBoundJSON(s.leftBoundAbs, s.leftBoundRel, s.leftBoundRelN),
+		Right:         makeBoundJSON(s.rightBoundAbs, s.rightBoundRel, s.rightBoundRelN),
+		LeftExcluded:  s.leftExcluded,
+		RightExcluded: s.rightExcluded,
+		Step:          s.step,
+		SessionGap:    s.sessionGap,
+		Location:      locationName(s.location),
+	})
+}
+
+// locationName returns the name of the time zone, an empty string if there is none
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.String()
+}
+
+// UnmarshalJSON reads the structured form of the specification, see MarshalJSON
+func (s *Specification) UnmarshalJSON(data []byte) (err error) {
+	var sj specificationJSON
+	if err = json.Unmarshal(data, &sj); err != nil {
+		return err
+	}
+
+	spec := Specification{
+		leftExcluded:  sj.LeftExcluded,
+		rightExcluded: sj.RightExcluded,
+		step:          sj.Step,
+		sessionGap:    sj.SessionGap,
+	}
+	if spec.leftBoundAbs, spec.leftBoundRel, spec.leftBoundRelN, err = sj.Left.read(); err != nil {
+		return err
+	}
+	if spec.rightBoundAbs, spec.rightBoundRel, spec.rightBoundRelN, err = sj.Right.read(); err != nil {
+		return err
+	}
+	if sj.Location != "" {
+		if spec.location, err = time.LoadLocation(sj.Location); err != nil {
+			return err
+		}
+	}
+	if err = spec.validate(); err != nil {
+		return err
+	}
+	*s = spec
+	return nil
+}
+
+// MarshalBinary returns a compact form of the specification
+func (s Specification) MarshalBinary() ([]byte, error) {
+	e := &binaryEncoder{}
+	e.buf.WriteByte(binaryVersion)
+	e.flags(s.leftExcluded, s.rightExcluded, s.location != nil)
+	if err := e.bound(s.leftBoundAbs, s.leftBoundRel, s.leftBoundRelN); err != nil {
+		return nil, err
+	}
+	if err := e.bound(s.rightBoundAbs, s.rightBoundRel, s.rightBoundRelN); err != nil {
+		return nil, err
+	}
+	e.optionalPeriod(s.step)
+	e.optionalPeriod(s.sessionGap)
+	if s.location != nil {
+		e.bytes([]byte(s.location.String()))
+	}
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary reads the compact form of the specification, see MarshalBinary
+func (s *Specification) UnmarshalBinary(data []byte) (err error) {
+	d, err := newBinaryDecoder(data)
+	if err != nil {
+		return err
+	}
+
+	spec := Specification{}
+	flags := d.flags(3)
+	spec.leftExcluded, spec.rightExcluded = flags[0], flags[1]
+	spec.leftBoundAbs, spec.leftBoundRel, spec.leftBoundRelN = d.bound()
+	spec.rightBoundAbs, spec.rightBoundRel, spec.rightBoundRelN = d.bound()
+	spec.step = d.optionalPeriod()
+	spec.sessionGap = d.optionalPeriod()
+	if flags[2] {
+		spec.location = d.location()
+	}
+	if err = d.finish(); err != nil {
+		return err
+	}
+	if err = spec.validate(); err != nil {
+		return err
+	}
+	*s = spec
+	return nil
+}
+
+// String returns the window in the grammar: "[FROM 2022-04-01T00:00:00Z TO 2022-04-02T00:00:00Z)" or
+// "WITHIN 30 days" for sliding windows
+func (w Window) String() string {
+	spec := Specification{leftExcluded: w.fromExcluded, rightExcluded: w.toExcluded}
+	if w.from != nil && w.to != nil {
+		spec.leftBoundAbs, spec.rightBoundAbs = w.from, w.to
+	} else if !w.slide.IsZero() {
+		spec.leftBoundRel = &w.slide
+	}
+	return spec.String()
+}
+
+// MarshalText returns the window in the grammar, see String
+func (w Window) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText reads a window from the grammar, the text must not be relative to now
+func (w *Window) UnmarshalText(text []byte) error {
+	spec, err := Start(string(text))
+	if err != nil {
+		return err
+	}
+	if spec.leftBoundRelN != nil || spec.rightBoundRelN != nil {
+		return fmt.Errorf("window %q is relative to now", text)
+	}
+	resolved, err := spec.TryResolveAt(time.Time{})
+	if err != nil {
+		return err
+	}
+	*w = *resolved
+	return nil
+}
+
+type windowJSON struct {
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	FromExcluded bool       `json:"from_excluded,omitempty"`
+	ToExcluded   bool       `json:"to_excluded,omitempty"`
+	Slide        *Period    `json:"slide,omitempty"`
+}
+
+// MarshalJSON returns resolved bounds of the window: {"from":"2022-04-01T00:00:00Z","to":"2022-04-02T00:00:00Z"}
+// or the slide of a sliding window: {"slide":{"days":30}}
+func (w Window) MarshalJSON() ([]byte, error) {
+	wj := windowJSON{From: w.from, To: w.to, FromExcluded: w.fromExcluded, ToExcluded: w.toExcluded}
+	if !w.slide.IsZero() {
+		wj.Slide = &w.slide
+	}
+	return json.Marshal(wj)
+}
+
+// UnmarshalJSON reads the window, see MarshalJSON
+func (w *Window) UnmarshalJSON(data []byte) error {
+	var wj windowJSON
+	if err := json.Unmarshal(data, &wj); err != nil {
+		return err
+	}
+	win := Window{from: wj.From, to: wj.To, fromExcluded: wj.FromExcluded, toExcluded: wj.ToExcluded}
+	if wj.Slide != nil {
+		win.slide = *wj.Slide
+	}
+	if err := win.validate(); err != nil {
+		return err
+	}
+	*w = win
+	return nil
+}
+
+// MarshalBinary returns a compact form of the window
+func (w Window) MarshalBinary() ([]byte, error) {
+	e := &binaryEncoder{}
+	e.buf.WriteByte(binaryVersion)
+	e.flags(w.fromExcluded, w.toExcluded, w.from != nil, w.to != nil)
+	for _, t := range []*time.Time{w.from, w.to} {
+		if t != nil {
+			if err := e.time(*t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	e.period(w.slide)
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary reads the compact form of the window, see MarshalBinary
+func (w *Window) UnmarshalBinary(data []byte) error {
+	d, err := newBinaryDecoder(data)
+	if err != nil {
+		return err
+	}
+
+	win := Window{}
+	flags := d.flags(4)
+	win.fromExcluded, win.toExcluded = flags[0], flags[1]
+	if flags[2] {
+		t := d.time()
+		win.from = &t
+	}
+	if flags[3] {
+		t := d.time()
+		win.to = &t
+	}
+	win.slide = d.period()
+	if err = d.finish(); err != nil {
+		return err
+	}
+	if err = win.validate(); err != nil {
+		return err
+	}
+	*w = win
+	return nil
+}
+
+// kinds of bounds in the binary form
+const (
+	binaryBoundNone byte = iota
+	binaryBoundAbs
+	binaryBoundRel
+	binaryBoundRelN
+)
+
+type binaryEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *binaryEncoder) varint(n int64) {
+	b := make([]byte, binary.MaxVarintLen64)
+	e.buf.Write(b[:binary.PutVarint(b, n)])
+}
+
+func (e *binaryEncoder) flags(flags ...bool) {
+	var b byte
+	for i, f := range flags {
+		if f {
+			b |= 1 << i
+		}
+	}
+	e.buf.WriteByte(b)
+}
+
+func (e *binaryEncoder) bytes(b []byte) {
+	e.varint(int64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *binaryEncoder) time(t time.Time) error {
+	b, err := t.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	e.bytes(b)
+	return nil
+}
+
+func (e *binaryEncoder) period(p Period) {
+	e.varint(int64(p.Years))
+	e.varint(int64(p.Months))
+	e.varint(int64(p.Days))
+	e.varint(int64(p.Duration))
+}
+
+func (e *binaryEncoder) optionalPeriod(p *Period) {
+	e.flags(p != nil)
+	if p != nil {
+		e.period(*p)
+	}
+}
+
+func (e *binaryEncoder) bound(abs *time.Time, rel *Period, relN *boundRelativeToNow) error {
+	switch {
+	case abs != nil:
+		e.buf.WriteByte(binaryBoundAbs)
+		return e.time(*abs)
+	case rel != nil:
+		e.buf.WriteByte(binaryBoundRel)
+		e.period(*rel)
+	case relN != nil:
+		e.buf.WriteByte(binaryBoundRelN)
+		e.flags(relN.inFuture, relN.anchor != nil, relN.count != 0, relN.full, relN.this, relN.year != 0, relN.fiscal,
+			relN.timeOfDay != "")
+		e.bytes([]byte(relN.verbal))
+		e.period(relN.duration)
+		e.bytes([]byte(relN.round))
+		if relN.count != 0 {
+			e.varint(int64(relN.count))
+		}
+		if relN.year != 0 {
+			e.varint(int64(relN.year))
+			e.varint(int64(relN.index))
+		}
+		if relN.timeOfDay != "" {
+			e.bytes([]byte(relN.timeOfDay))
+		}
+		if relN.anchor != nil {
+			return e.bound(relN.anchor.abs, nil, relN.anchor.relN)
+		}
+	default:
+		e.buf.WriteByte(binaryBoundNone)
+	}
+	return nil
+}
+
+// binaryDecoder reads values in the order they were written by binaryEncoder.
+// The first error is kept and reported by finish, following reads return zero values.
+type binaryDecoder struct {
+	r       *bytes.Reader
+	version byte
+	err     error
+}
+
+func newBinaryDecoder(data []byte) (*binaryDecoder, error) {
+	if len(data) == 0 || data[0] == 0 || data[0] > binaryVersion {
+		return nil, errBinaryFormat
+	}
+	return &binaryDecoder{r: bytes.NewReader(data[1:]), version: data[0]}, nil
+}
+
+func (d *binaryDecoder) finish() error {
+	if d.err == nil && d.r.Len() > 0 {
+		d.err = errBinaryFormat
+	}
+	return d.err
+}
+
+func (d *binaryDecoder) fail(err error) {
+	if d.err == nil && err != nil {
+		d.err = errBinaryFormat
+	}
+}
+
+func (d *binaryDecoder) varint() int64 {
+	n, err := binary.ReadVarint(d.r)
+	d.fail(err)
+	return n
+}
+
+func (d *binaryDecoder) byte() byte {
+	b, err := d.r.ReadByte()
+	d.fail(err)
+	return b
+}
+
+// flags reads a byte of flags, only the first known flags can be set
+func (d *binaryDecoder) flags(known int) (flags [8]bool) {
+	b := d.byte()
+	if b>>known != 0 {
+		d.fail(errBinaryFormat)
+	}
+	for i := range flags {
+		flags[i] = b&(1<<i) != 0
+	}
+	return
+}
+
+func (d *binaryDecoder) bytes() []byte {
+	n := d.varint()
+	if n < 0 || n > int64(d.r.Len()) {
+		d.fail(errBinaryFormat)
+		return nil
+	}
+	b := make([]byte, n)
+	if n > 0 {
+		_, err := d.r.Read(b)
+		d.fail(err)
+	}
+	return b
+}
+
+func (d *binaryDecoder) time() (t time.Time) {
+	d.fail(t.UnmarshalBinary(d.bytes()))
+	return t
+}
+
+func (d *binaryDecoder) location() *time.Location {
+	name := string(d.bytes())
+	if d.err != nil {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	d.fail(err)
+	return loc
+}
+
+func (d *binaryDecoder) period() Period {
+	return Period{
+		Years:    int(d.varint()),
+		Months:   int(d.varint()),
+		Days:     int(d.varint()),
+		Duration: time.Duration(d.varint()),
+	}
+}
+
+func (d *binaryDecoder) optionalPeriod() *Period {
+	if !d.flags(1)[0] {
+		return nil
+	}
+	p := d.period()
+	return &p
+}
+
+func (d *binaryDecoder) bound() (abs *time.Time, rel *Period, relN *boundRelativeToNow) {
+	switch d.byte() {
+	case binaryBoundNone:
+	case binaryBoundAbs:
+		t := d.time()
+		abs = &t
+	case binaryBoundRel:
+		p := d.period()
+		rel = &p
+	case binaryBoundRelN:
+		flags := d.flags(8)
+		relN = &boundRelativeToNow{inFuture: flags[0], full: flags[3], this: flags[4], fiscal: flags[6]}
+		relN.verbal = string(d.bytes())
+		relN.duration = d.period()
+		if d.version == 1 {
+			d.fail(relN.validate())
+			return
+		}
+		relN.round = string(d.bytes())
+		if flags[2] {
+			relN.count = int(d.varint())
+		}
+		if flags[5] {
+			relN.year = int(d.varint())
+			relN.index = int(d.varint())
+		}
+		if flags[7] {
+			relN.timeOfDay = string(d.bytes())
+		}
+		if flags[1] {
+			anchor := &boundAnchor{}
+			var anchorRel *Period
+			anchor.abs, anchorRel, anchor.relN = d.bound()
+			if anchorRel != nil {
+				d.fail(errBinaryFormat)
+			}
+			relN.anchor = anchor
+		}
+		d.fail(relN.validate())
+	default:
+		d.fail(errBinaryFormat)
+	}
+	return
+}
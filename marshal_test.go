@@ -0,0 +1,233 @@
+package window
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/araddon/dateparse"
+)
+
+var marshalingTexts = []string{
+	"1 Jan 1991 to 2 Feb 1992",
+	"2022-04-01T10:00:00.123456789+03:30 within 1 day",
+	"1 April 2022 to tomorrow",
+	"1 day to 2 April 2022",
+	"within 1 year and 3 months and 2 hours",
+	"3 days until last year",
+	"30 days until 2 days ago",
+	"next year within 3 days and 2 hours",
+	"from last month until 2 hours later",
+	"[from last monday to next june) by 1 hour",
+	"(30 minutes] every 5 minutes",
+	"from now-7d/d to now+1M-2h/h",
+	"from now-1y-6M/d to now+1h-1d",
+	"from last 3 full weeks to next 10 days",
+	"[from this hour to next 2 full days)",
+	"QTD",
+	"from 2021-W52 to 2022-W01",
+	"from fiscal 2021-Q1 to 2022-H1",
+	"from last half to next 3 full quarters",
+	"from 2022-04-02 to yesterday in Europe/Berlin",
+	"[MTD) by 1 day TZ=America/Denver",
+	"from 3 days before last monday to 2 hours after tomorrow",
+	"from 1 hour after 2 days before 2022-04-01T00:00:00Z to now",
+	"[from yesterday at 9am to today end of day)",
+	"from 1 hour before last friday 17:30 to tomorrow midnight",
+	"session gap 30 minutes",
+}
+
+func TestSpecification_Marshaling(t *testing.T) {
+	for i, text := range marshalingTexts {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// text
+			var fromText Specification
+			data, _ := spec.MarshalText()
+			if err = fromText.UnmarshalText(data); err != nil {
+				t.Fatal(err)
+			}
+			if !fromText.Equal(spec) {
+				t.Errorf("text [%s] is not equal to [%s]", fromText, spec)
+			}
+
+			// JSON
+			var fromJSON Specification
+			data, err = json.Marshal(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = json.Unmarshal(data, &fromJSON); err != nil {
+				t.Fatal(err)
+			}
+			if !fromJSON.Equal(spec) {
+				t.Errorf("JSON %s is not equal to [%s]", data, spec)
+			}
+
+			// binary
+			var fromBinary Specification
+			data, _ = spec.MarshalBinary()
+			if err = fromBinary.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !fromBinary.Equal(spec) {
+				t.Errorf("binary [%s] is not equal to [%s]", fromBinary, spec)
+			}
+		})
+	}
+}
+
+func TestSpecification_MarshalJSON(t *testing.T) {
+	spec, _ := Start("[from last week to 2 days and 3 hours ago) by 1 month")
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"left":{"kind":"relative_to_now","verbal":"week"},` +
+		`"right":{"kind":"relative_to_now","period":{"days":2,"duration":"3h0m0s"}},` +
+		`"right_excluded":true,"step":{"months":1}}`
+	if string(data) != expected {
+		t.Errorf("JSON %s should be %s", data, expected)
+	}
+}
+
+func TestSpecification_UnmarshalFail(t *testing.T) {
+	type test struct {
+		json string
+	}
+	tests := []test{
+		{`{"left":{"kind":"sometime"}}`},
+		{`{"left":{"kind":"absolute"}}`},
+		{`{"left":{"kind":"relative"}}`},
+		{`{"left":{"kind":"relative_to_now","verbal":"fortnight"}}`},
+		{`{"left":{"kind":"relative","period":{"days":1}},"right":{"kind":"relative","period":{"days":1}}}`},
+		{`{"step":{"duration":"1 hour"}}`},
+		{`{"left":{"kind":"relative_to_now","period":{"days":1},"anchor":{"kind":"relative","period":{"days":1}}}}`},
+		{`{"left":{"kind":"relative_to_now","verbal":"week","anchor":{"kind":"relative_to_now","verbal":"now"}}}`},
+		{`{"left":{"kind":"relative_to_now","verbal":"week","time_of_day":"09:00:00"}}`},
+		{`{"left":{"kind":"relative_to_now","verbal":"today","time_of_day":"9am"}}`},
+		{`{"left":{"kind":"relative_to_now","period":{"days":1},"anchor":{"kind":"relative_to_now","verbal":"fortnight"}}}`},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			var spec Specification
+			if err := json.Unmarshal([]byte(tt.json), &spec); err == nil {
+				t.Errorf("JSON %s should not be accepted", tt.json)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		json string
+		err  error
+	}{
+		{`{}`, ErrEmptyWindow},
+		{`{"right":{"kind":"relative_to_now","verbal":"now"}}`, ErrEmptyWindow},
+		{`{"left":{"kind":"relative_to_now","verbal":"week"},"session_gap":{"duration":"1h0m0s"}}`, ErrSessionHasNoBounds},
+		{`{"step":{"days":1},"session_gap":{"duration":"1h0m0s"}}`, ErrSessionHasNoBounds},
+		{`{"session_gap":{"duration":"-1h0m0s"}}`, ErrInvalidStep},
+		{`{"left":{"kind":"relative_to_now","verbal":"week"},"step":{}}`, ErrInvalidStep},
+	} {
+		var spec Specification
+		if err := json.Unmarshal([]byte(tt.json), &spec); !errors.Is(err, tt.err) {
+			t.Errorf("error [%v] for JSON %s should be [%v]", err, tt.json, tt.err)
+		}
+	}
+
+	spec, _ := Start("last week to today")
+	data, _ := spec.MarshalBinary()
+	newer := append([]byte{binaryVersion + 1}, data[1:]...)
+	unknownFlag := append([]byte{data[0], data[1] | 1<<7}, data[2:]...)
+	empty, _ := Specification{}.MarshalBinary()
+	for _, broken := range [][]byte{nil, {0}, data[:len(data)-1], append(data, 0), newer, unknownFlag, empty} {
+		if err := spec.UnmarshalBinary(broken); err == nil {
+			t.Errorf("binary %v should not be accepted", broken)
+		}
+	}
+}
+
+func TestSpecification_UnmarshalBinaryVersion1(t *testing.T) {
+	// "[from last week to 2 days and 3 hours ago) by 1 day" in the first version of the layout
+	data := []byte{0x1, 0x2, 0x3, 0x0, 0x8, 0x77, 0x65, 0x65, 0x6b, 0x0, 0x0, 0x0, 0x0, 0x3, 0x0, 0x0, 0x0, 0x0, 0x4,
+		0x80, 0x80, 0xcf, 0xa2, 0xd2, 0xf4, 0x4, 0x1, 0x0, 0x0, 0x2, 0x0, 0x0}
+	var spec Specification
+	if err := spec.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := Start("[from last week to 2 days and 3 hours ago) by 1 day")
+	if !spec.Equal(expected) {
+		t.Errorf("binary [%s] is not equal to [%s]", spec, expected)
+	}
+}
+
+func TestWindow_Marshaling(t *testing.T) {
+	now := dateparse.MustParse("1 May 2022 00:00:00")
+	for i, text := range marshalingTexts[:len(marshalingTexts)-1] {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			win := spec.ResolveAt(now)
+
+			// text
+			var fromText Window
+			data, _ := win.MarshalText()
+			if err = fromText.UnmarshalText(data); err != nil {
+				t.Fatal(err)
+			}
+			if !fromText.Equal(win) {
+				t.Errorf("text [%s] is not equal to [%s]", fromText, win)
+			}
+
+			// JSON
+			var fromJSON Window
+			data, err = json.Marshal(win)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = json.Unmarshal(data, &fromJSON); err != nil {
+				t.Fatal(err)
+			}
+			if !fromJSON.Equal(win) {
+				t.Errorf("JSON %s is not equal to [%s]", data, win)
+			}
+
+			// binary
+			var fromBinary Window
+			data, _ = win.MarshalBinary()
+			if err = fromBinary.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !fromBinary.Equal(win) {
+				t.Errorf("binary [%s] is not equal to [%s]", fromBinary, win)
+			}
+		})
+	}
+
+	var w Window
+	if err := w.UnmarshalText([]byte("yesterday to today")); err == nil {
+		t.Errorf("window relative to now should not be accepted")
+	}
+	if err := json.Unmarshal([]byte(`{"from":"2022-04-02T00:00:00Z","to":"2022-04-01T00:00:00Z"}`), &w); err == nil {
+		t.Errorf("window with bounds in wrong order should not be accepted")
+	}
+}
+
+func TestWindow_MarshalJSON(t *testing.T) {
+	w := resolveWindow(t, "[2022-04-01T00:00:00Z to 2022-04-02T00:00:00Z)")
+	data, _ := json.Marshal(w)
+	if expected := `{"from":"2022-04-01T00:00:00Z","to":"2022-04-02T00:00:00Z","to_excluded":true}`; string(data) != expected {
+		t.Errorf("JSON %s should be %s", data, expected)
+	}
+	data, _ = json.Marshal(resolveWindow(t, "30 days"))
+	if expected := `{"slide":{"days":30}}`; string(data) != expected {
+		t.Errorf("JSON %s should be %s", data, expected)
+	}
+}
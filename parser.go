@@ -2,20 +2,32 @@ package window
 
 import (
 	"regexp"
-	"strings"
 )
 
 type Parser struct {
-	text string
-	pos  int
+	text     string // lower-cased text for matching
+	original string // the text as given, it has the same length as text
+	pos      int
 }
 
 func startParsing(text string) *Parser {
 	return &Parser{
-		text: strings.ToLower(text),
+		text:     lowerASCII(text),
+		original: text,
 	}
 }
 
+// lowerASCII converts only ASCII letters, so byte offsets in the result match the given text
+func lowerASCII(text string) string {
+	b := []byte(text)
+	for i, ch := range b {
+		if 'A' <= ch && ch <= 'Z' {
+			b[i] = ch + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
 // expectAny compares the remaining string against any of given alternatives
 // returns the consumed alternative (empty string if not matched)
 func (p *Parser) expectAny(alts []string) string {
@@ -28,6 +40,14 @@ func (p *Parser) expectAny(alts []string) string {
 	return "" // matched nothing
 }
 
+// peekAny is the same as expectAny but it does not consume the matched alternative
+func (p *Parser) peekAny(alts []string) string {
+	pos := p.pos
+	matched := p.expectAny(alts)
+	p.rollbackAt(pos)
+	return matched
+}
+
 // expect consumes the given string if it matched the current remainder
 func (p *Parser) expect(expected string) bool {
 	if len(expected) <= len(p.text[p.pos:]) && expected == p.text[p.pos:p.pos+len(expected)] {
@@ -38,9 +58,12 @@ func (p *Parser) expect(expected string) bool {
 }
 
 // consumeUntil consumes all bytes from the text until it meets one of the alternatives (the alt is NOT consumed)
-// it returns the consumed string and the found alternative
+// it returns the consumed string (in the original case) and the found alternative
 // it advances the position to after the found alternative
 func (p *Parser) consumeUntil(alts []string) (consumed, matchedAlt string) {
+	start := p.pos
+	defer func() { consumed = p.original[start:p.pos] }()
+
 	for p.pos < len(p.text) {
 		for _, alt := range alts {
 			if len(alt) <= len(p.text[p.pos:]) && alt == p.text[p.pos:p.pos+len(alt)] {
@@ -50,7 +73,6 @@ func (p *Parser) consumeUntil(alts []string) (consumed, matchedAlt string) {
 		}
 
 		// not matched, consume the char
-		consumed = string(append([]byte(consumed), p.text[p.pos]))
 		p.pos += 1
 	}
 	return
@@ -0,0 +1,154 @@
+package window
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+// Period is an amount of time made of calendar components (years, months, days) and a clock duration.
+// Calendar components do not have a fixed length, so they are kept separately and applied to a time point with
+// the calendar of its location, see AddTo.
+type Period struct {
+	Years, Months, Days int
+	Duration            time.Duration
+}
+
+// AddTo applies the period to the given time point.
+// Years and months are applied first. If the day of month does not exist in the resulting month, it is clamped
+// to the last day of that month: 31 March plus (minus) 1 month is 30 April (28 February).
+// Then days are added (calendar days, so a day may last 23 or 25 hours around DST transitions)
+// and finally the clock duration.
+func (p Period) AddTo(t time.Time) time.Time {
+	if p.Years != 0 || p.Months != 0 {
+		y, m, d := t.Date()
+		target := time.Date(y+p.Years, m+time.Month(p.Months), 1, 0, 0, 0, 0, t.Location())
+		if lastDay := daysInMonth(target); d > lastDay {
+			d = lastDay
+		}
+		t = time.Date(target.Year(), target.Month(), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	if p.Days != 0 { // AddDate normalizes the time, it would move the repeated hour of a DST transition to the first one
+		t = t.AddDate(0, 0, p.Days)
+	}
+	return t.Add(p.Duration)
+}
+
+// SubFrom applies the negated period to the given time point, see AddTo
+func (p Period) SubFrom(t time.Time) time.Time {
+	return p.Neg().AddTo(t)
+}
+
+// Neg returns the period with all components negated
+func (p Period) Neg() Period {
+	return Period{Years: -p.Years, Months: -p.Months, Days: -p.Days, Duration: -p.Duration}
+}
+
+// Plus returns the sum of two periods, components are summed separately
+func (p Period) Plus(o Period) Period {
+	return Period{
+		Years:    p.Years + o.Years,
+		Months:   p.Months + o.Months,
+		Days:     p.Days + o.Days,
+		Duration: p.Duration + o.Duration,
+	}
+}
+
+// Times multiplies each component of the period by n.
+// It panics if a component overflows, see TryTimes.
+func (p Period) Times(n int) Period {
+	t, err := p.TryTimes(n)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TryTimes is the same as Times but returns ErrPeriodOverflow instead of panicking
+func (p Period) TryTimes(n int) (Period, error) {
+	years, yearsOk := mulInt(p.Years, n)
+	months, monthsOk := mulInt(p.Months, n)
+	days, daysOk := mulInt(p.Days, n)
+	duration := p.Duration * time.Duration(n)
+	if !yearsOk || !monthsOk || !daysOk || n != 0 && duration/time.Duration(n) != p.Duration ||
+		n == -1 && p.Duration == math.MinInt64 {
+		return Period{}, ErrPeriodOverflow
+	}
+	return Period{Years: years, Months: months, Days: days, Duration: duration}, nil
+}
+
+// tryPlus is the same as Plus but returns ErrPeriodOverflow if a component overflows
+func (p Period) tryPlus(o Period) (Period, error) {
+	sum := p.Plus(o)
+	if (sum.Years > p.Years) != (o.Years > 0) || (sum.Months > p.Months) != (o.Months > 0) ||
+		(sum.Days > p.Days) != (o.Days > 0) || (sum.Duration > p.Duration) != (o.Duration > 0) {
+		return Period{}, ErrPeriodOverflow
+	}
+	return sum, nil
+}
+
+// mulInt returns a*b, false if it overflows
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	return c, c/b == a && !(a == -1 && b == math.MinInt) && !(b == -1 && a == math.MinInt)
+}
+
+// scale multiplies the period by a decimal fraction num/den: 1.5 is scale(15, 10). The period and the fraction must
+// not be negative.
+// Fractions of days and weeks are converted to the clock duration (1.5 days is 1 day and 12 hours), fractions of
+// months and years are not allowed unless they make whole months (1.5 years is 1 year and 6 months).
+// It returns ErrPeriodOverflow if the result does not fit the period.
+func (p Period) scale(num, den int) (Period, error) {
+	months, monthsRem, monthsOk := mulAddDiv(uint64(p.Years*12+p.Months), uint64(num), 0, 0, uint64(den))
+	if monthsRem != 0 {
+		return Period{}, fmt.Errorf("fractional months are not supported")
+	}
+	days, daysRem, daysOk := mulAddDiv(uint64(p.Days), uint64(num), 0, 0, uint64(den))
+	clock, _, clockOk := mulAddDiv(daysRem, uint64(24*time.Hour), uint64(p.Duration), uint64(num), uint64(den))
+	if !monthsOk || !daysOk || !clockOk || months > math.MaxInt || days > math.MaxInt || clock > math.MaxInt64 {
+		return Period{}, ErrPeriodOverflow
+	}
+
+	scaled := Period{Days: int(days), Duration: time.Duration(clock)}
+	if p.Months == 0 {
+		scaled.Years, scaled.Months = int(months/12), int(months%12)
+	} else {
+		scaled.Months = int(months)
+	}
+	return scaled, nil
+}
+
+// mulAddDiv returns the quotient and the remainder of (a*b + c*d) / den computed without an overflow of the
+// intermediate values, false if the quotient does not fit 64 bits
+func mulAddDiv(a, b, c, d, den uint64) (quo, rem uint64, ok bool) {
+	hi, lo := bits.Mul64(a, b)
+	hi2, lo2 := bits.Mul64(c, d)
+	lo, carry := bits.Add64(lo, lo2, 0)
+	hi, overflow := bits.Add64(hi, hi2, carry)
+	if overflow != 0 || hi >= den {
+		return 0, 0, false
+	}
+	quo, rem = bits.Div64(hi, lo, den)
+	return quo, rem, true
+}
+
+// IsZero returns true if the period has no length
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+// IsCalendar returns true if the period has components without a fixed length (years, months or days)
+func (p Period) IsCalendar() bool {
+	return p.Years != 0 || p.Months != 0 || p.Days != 0
+}
+
+// Approximate returns the period as a fixed duration.
+// A day is counted as 24 hours, a month as 30 days and a year as 365 days.
+func (p Period) Approximate() time.Duration {
+	days := time.Duration(p.Years*365 + p.Months*30 + p.Days)
+	return days*24*time.Hour + p.Duration
+}
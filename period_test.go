@@ -0,0 +1,84 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func TestPeriod_AddTo(t *testing.T) {
+	type test struct {
+		at       string
+		period   Period
+		expected string
+	}
+	tests := []test{
+		{"31 Mar 2022 10:00:00", Period{Months: -1}, "28 Feb 2022 10:00:00"},
+		{"31 Mar 2022 10:00:00", Period{Months: 1}, "30 Apr 2022 10:00:00"},
+		{"31 Jan 2024 10:00:00", Period{Months: 1}, "29 Feb 2024 10:00:00"},
+		{"29 Feb 2024 10:00:00", Period{Years: 1}, "28 Feb 2025 10:00:00"},
+		{"29 Feb 2024 10:00:00", Period{Years: -4}, "29 Feb 2020 10:00:00"},
+		{"15 Nov 2022 10:00:00", Period{Months: 3}, "15 Feb 2023 10:00:00"},
+		{"31 Mar 2022 10:00:00", Period{Months: -1, Days: 1}, "1 Mar 2022 10:00:00"},
+		{"31 Dec 2022 23:00:00", Period{Days: 1, Duration: 2 * time.Hour}, "2 Jan 2023 01:00:00"},
+		{"1 May 2022 00:00:00", Period{Days: 3}.Neg(), "28 Apr 2022 00:00:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			at := dateparse.MustParse(tt.at)
+			expected := dateparse.MustParse(tt.expected)
+			if r := tt.period.AddTo(at); !r.Equal(expected) {
+				t.Errorf("result [%s] should be [%s]", r, expected)
+			}
+		})
+	}
+}
+
+func TestPeriod_Approximate(t *testing.T) {
+	p := Period{Years: 1, Months: 1, Days: 1, Duration: time.Hour}
+	if d := p.Approximate(); d != (365+30+1)*24*time.Hour+time.Hour {
+		t.Errorf("unexpected approximation %s", d)
+	}
+}
+
+func TestPeriod_TryTimes(t *testing.T) {
+	if p, err := (Period{Days: 7, Duration: time.Hour}).TryTimes(3); err != nil || p != (Period{Days: 21, Duration: 3 * time.Hour}) {
+		t.Errorf("unexpected product %v, %v", p, err)
+	}
+	if _, err := (Period{Duration: time.Hour}).TryTimes(3000000); !errors.Is(err, ErrPeriodOverflow) {
+		t.Errorf("error [%v] should be [%v]", err, ErrPeriodOverflow)
+	}
+	if _, err := (Period{Years: 2}).TryTimes(math.MaxInt); !errors.Is(err, ErrPeriodOverflow) {
+		t.Errorf("error [%v] should be [%v]", err, ErrPeriodOverflow)
+	}
+}
+
+func TestPeriod_scale(t *testing.T) {
+	type test struct {
+		period   Period
+		num, den int
+		expected Period
+		err      error
+	}
+	tests := []test{
+		{Period{Days: 1}, 15, 10, Period{Days: 1, Duration: 12 * time.Hour}, nil},
+		{Period{Years: 1}, 15, 10, Period{Years: 1, Months: 6}, nil},
+		{Period{Duration: time.Hour}, 1123456789, 1000000000, Period{Duration: 4044444440400}, nil},
+		{Period{Duration: time.Hour}, 3000000, 1, Period{}, ErrPeriodOverflow},
+		{Period{Days: 7}, math.MaxInt, 1, Period{}, ErrPeriodOverflow},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			p, err := tt.period.scale(tt.num, tt.den)
+			if !errors.Is(err, tt.err) || p != tt.expected {
+				t.Errorf("result [%v, %v] should be [%v, %v]", p, err, tt.expected, tt.err)
+			}
+		})
+	}
+}
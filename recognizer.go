@@ -2,6 +2,9 @@ package window
 
 import (
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +15,8 @@ import (
 const (
 	STATE_LEFT_BOUND  = iota // parse left bound
 	STATE_RIGHT_BOUND        // parse right bound
+	STATE_STEP               // parse optional step "by 1 hour"
+	STATE_TIMEZONE           // parse optional time zone "in Europe/Berlin"
 	STATE_VALIDATE           // parsing is over, validate the result
 	STATE_FINISH             // all is good, stop the parsing
 )
@@ -19,20 +24,75 @@ const (
 type Recognizer struct {
 	p    *Parser
 	spec Specification
+	// closingBrackets is set when the text starts with a bracket: "[from X to Y)"
+	closingBrackets []string
+	// absTimes are absolute bounds and anchors with their texts, they are parsed again in the time zone of the text
+	absTimes []parsedTime
+}
+
+// parsedTime is an absolute time parsed from the text
+type parsedTime struct {
+	text string
+	t    *time.Time
 }
 
 func (r *Recognizer) state(state int) (nextState int, err error) {
 	r.p.eatWs()
 	switch state {
 	case STATE_LEFT_BOUND: // start here
+		// session window "session gap 30 minutes" has no bounds
+		if r.p.expect("session") {
+			r.p.eatWs()
+			r.p.expect("gap")
+			gap, gapErr := r.parseRelBound()
+			if gapErr != nil {
+				err = r.boundFailure(SideLeft, gapErr)
+				return
+			}
+			r.spec.sessionGap = &gap
+			nextState = STATE_VALIDATE
+			return
+		}
+
+		// bounds inclusivity "[from X to Y)"
+		switch r.p.expectAny([]string{"[", "("}) {
+		case "(":
+			r.spec.leftExcluded = true
+			fallthrough
+		case "[":
+			r.closingBrackets = []string{"]", ")"}
+			r.p.eatWs()
+		}
+
+		// period to date "MTD" makes both bounds
+		if unit := getToDateWords()[r.p.expectAny(toDateWords())]; unit != "" {
+			r.spec.leftBoundRelN = makeDateMathBound(nil, unit)
+			r.spec.rightBoundRelN = &boundRelativeToNow{verbal: "now"}
+			nextState = STATE_STEP
+			return
+		}
+
 		// skip keywords
-		r.p.expectAny([]string{"from", "since", "within"})
+		keyword := r.p.expectAny([]string{"from", "since", "within"})
 		r.p.eatWs()
 
+		// an offset after WITHIN has no anchor, the right bound follows it: "within 3 days before x" is the window
+		// from 3 days ago to x
+		if keyword == "within" {
+			oldPos := r.p.pos
+			var bound boundRelativeToNow
+			if _, offsetErr := r.parseOffset(&bound); offsetErr == nil {
+				r.spec.leftBoundRelN = &bound
+				nextState = STATE_RIGHT_BOUND
+				return
+			}
+			r.p.rollbackAt(oldPos)
+		}
+
 		// Try 1: RelN spec
 		oldPos := r.p.pos
-		bound, parseErr := r.parseRelnBound()
-		if parseErr == nil {
+		bound, relnErr := r.parseRelnBound()
+		if relnErr == nil {
 			r.spec.leftBoundRelN = &bound
 			nextState = STATE_RIGHT_BOUND
 			return
@@ -41,8 +101,8 @@ func (r *Recognizer) state(state int) (nextState int, err error) {
 
 		// Try 2: Rel spec
 		oldPos = r.p.pos
-		duration, parseErr := r.parseRelBound()
-		if parseErr == nil {
+		duration, relErr := r.parseRelBound()
+		if relErr == nil && !r.failedAhead(relnErr) {
 			r.spec.leftBoundRel = &duration
 			nextState = STATE_RIGHT_BOUND
 			return
@@ -51,22 +111,23 @@ func (r *Recognizer) state(state int) (nextState int, err error) {
 
 		// Try 3: anything else should be treated as Abs spec
 		oldPos = r.p.pos
-		leftBoundText, _ := r.p.consumeUntil([]string{" to", "until", "within"})
+		leftBoundText, _ := r.p.consumeUntil([]string{" to", "until", "within", " in ", " tz="})
 		leftBoundText = strings.Trim(leftBoundText, " \n\t")
 		absTime, absErr := dateparse.ParseStrict(leftBoundText)
 		if absErr == nil {
 			r.spec.leftBoundAbs = &absTime
+			r.absTimes = append(r.absTimes, parsedTime{leftBoundText, &absTime})
 			nextState = STATE_RIGHT_BOUND
 			return
 		}
 		r.p.rollbackAt(oldPos)
 
-		err = fmt.Errorf("failed to recognize the left bound")
+		err = r.boundFailure(SideLeft, relnErr, relErr, r.fail("", "date"))
 		return
 
 	case STATE_RIGHT_BOUND:
-		if r.p.isEof() { // sliding window case
-			nextState = STATE_VALIDATE
+		if r.p.isEof() || r.p.peekAny(append([]string{"by ", "every ", "in ", "tz="}, r.closingBrackets...)) != "" { // sliding window case
+			nextState = STATE_STEP
 			return
 		}
 
@@ -75,43 +136,74 @@ func (r *Recognizer) state(state int) (nextState int, err error) {
 
 		// Try 1: RelN spec
 		oldPos := r.p.pos
-		bound, parseErr := r.parseRelnBound()
-		if parseErr == nil {
+		bound, relnErr := r.parseRelnBound()
+		if relnErr == nil {
 			r.spec.rightBoundRelN = &bound
-			nextState = STATE_VALIDATE
+			nextState = STATE_STEP
 			return
 		}
 		r.p.rollbackAt(oldPos)
 
 		// Try 2: Rel spec
 		oldPos = r.p.pos
-		duration, parseErr := r.parseRelBound()
-		if parseErr == nil {
+		duration, relErr := r.parseRelBound()
+		if relErr == nil && !r.failedAhead(relnErr) {
 			r.spec.rightBoundRel = &duration
-			nextState = STATE_VALIDATE
+			nextState = STATE_STEP
 			return
 		}
 		r.p.rollbackAt(oldPos)
 
 		// Try 3: anything else should be treated as Abs spec
-		remainingText, _ := r.p.consumeUntil([]string{})
+		oldPos = r.p.pos
+		remainingText, _ := r.p.consumeUntil(r.boundTerminators())
 		remainingText = strings.Trim(remainingText, " \n\t")
 		absTime, absErr := dateparse.ParseStrict(remainingText)
 		if absErr == nil {
 			r.spec.rightBoundAbs = &absTime
-			nextState = STATE_VALIDATE
+			r.absTimes = append(r.absTimes, parsedTime{remainingText, &absTime})
+			nextState = STATE_STEP
 			return
 		}
+		r.p.rollbackAt(oldPos)
 
-		err = fmt.Errorf("failed to recognize the right bound")
+		err = r.boundFailure(SideRight, relnErr, relErr, r.fail("", "date"))
 		return
 
+	case STATE_STEP:
+		if r.closingBrackets != nil && !r.expectClosingBracket() {
+			err = r.boundFailure(SideRight, r.fail("", r.closingBrackets...))
+			return
+		}
+		r.p.eatWs()
+		if r.p.expectAny([]string{"by", "every"}) != "" {
+			step, stepErr := r.parseRelBound()
+			if stepErr != nil {
+				err = r.boundFailure(SideRight, stepErr)
+				return
+			}
+			r.spec.step = &step
+		}
+		nextState = STATE_TIMEZONE
+
+	case STATE_TIMEZONE:
+		if r.p.expect("tz=") || r.p.expect("in ") {
+			r.p.eatWs()
+			if zoneErr := r.parseTimeZone(); zoneErr != nil {
+				err = r.boundFailure(SideRight, zoneErr)
+				return
+			}
+		}
+		nextState = STATE_VALIDATE
+
 	case STATE_VALIDATE:
 		if !r.p.isEof() { // at this point there should be nothing left in the string
-			err = r.fail("")
+			err = r.boundFailure(SideRight, r.fail("", "end of text"))
+			return
+		}
+		if err = r.spec.validate(); err != nil {
 			return
 		}
-		r.spec.validate()
 		nextState = STATE_FINISH
 	default:
 		err = fmt.Errorf("unexpected state %d", state)
@@ -121,82 +213,200 @@ func (r *Recognizer) state(state int) (nextState int, err error) {
 	return
 }
 
-func (r *Recognizer) fail(customMsg string) error {
+// boundTerminators returns alternatives which finish the right bound: a closing bracket, a step or a time zone clause
+func (r *Recognizer) boundTerminators() []string {
+	return append([]string{" by ", " every ", " in ", " tz="}, r.closingBrackets...)
+}
 
-	// Normal case:
-	msg := fmt.Sprintf("unexpected character found at %d", r.p.pos)
-	if customMsg != "" {
-		msg += ": " + customMsg
+// parseTimeZone parses an IANA time zone name "Europe/Berlin" in its original case. Zone-less absolute bounds
+// are parsed again in the zone.
+func (r *Recognizer) parseTimeZone() *ParseError {
+	start := r.p.pos
+	name := r.p.original[start : start+len(r.p.consumeRE(`^[a-z0-9_+\-/]+`))]
+	loc, locErr := time.LoadLocation(name)
+	if name == "" || locErr != nil {
+		r.p.rollbackAt(start)
+		reason := ""
+		if locErr != nil {
+			reason = locErr.Error()
+		}
+		return r.fail(reason, "time zone")
 	}
-	if len(r.p.text) > 0 {
-		visualPlacement := fmt.Sprintf("\n%s\n%s^", r.p.text, strings.Repeat(" ", r.p.pos))
-		msg += visualPlacement
+	r.spec.location = loc
+
+	for _, b := range r.absTimes {
+		t, absErr := dateparse.ParseIn(b.text, loc)
+		if absErr != nil {
+			return r.fail(absErr.Error(), "date")
+		}
+		*b.t = t
 	}
+	return nil
+}
 
-	return fmt.Errorf(msg)
+// expectClosingBracket consumes the bracket which closes "[from X to Y)" and sets the right bound inclusivity
+func (r *Recognizer) expectClosingBracket() bool {
+	if r.closingBrackets == nil {
+		return false
+	}
+	switch r.p.expectAny(r.closingBrackets) {
+	case ")":
+		r.spec.rightExcluded = true
+		fallthrough
+	case "]":
+		r.closingBrackets = nil
+		return true
+	}
+	return false
+}
+
+// fail makes a parse error at the current position
+func (r *Recognizer) fail(reason string, expected ...string) *ParseError {
+	return newParseError(r.p.original, r.p.pos, reason, expected...)
+}
+
+// boundFailure picks the most relevant error among all attempts to recognize a bound
+func (r *Recognizer) boundFailure(side BoundSide, attempts ...*ParseError) error {
+	var best *ParseError
+	for _, attempt := range attempts {
+		best = best.merge(attempt)
+	}
+	best.Side = side
+	return best
 }
 
-func (r *Recognizer) mapDurationUnit(unit string) (d time.Duration, err error) {
-	d = mapUnitToDuration(unit)
-	if d == 0 {
+func (r *Recognizer) mapDurationUnit(unit string) (p Period, err error) {
+	p = mapUnitToPeriod(unit)
+	if p.IsZero() {
 		err = fmt.Errorf("unsupported unit %s in relative bound", unit)
 	}
 	return
 }
 
-// parseRelBound check the current text and parses strings like "1 day" or "2 minutes and 3 seconds"
-func (r *Recognizer) parseRelBound() (d time.Duration, err error) {
+// parseRelBound check the current text and parses strings like "1 day" or "2 minutes and 3 seconds".
+// Numbers may be fractional ("1.5 hours") and units may be abbreviated Go-style: "7d", "1h30m", "90s".
+func (r *Recognizer) parseRelBound() (d Period, err *ParseError) {
 	r.p.eatWs()
+	start := r.p.pos
 	// parse num
-	num := r.p.consumeRE(`\d+`)
-	if num == "" {
-		err = r.fail("")
-		return
-	}
-	n, intErr := strconv.ParseInt(num, 10, 64)
-	if intErr != nil {
-		r.p.rollback(len(num))
-		err = r.fail(intErr.Error())
+	n, den, numErr := r.parseNumber()
+	if numErr != nil {
+		err = numErr
 		return
 	}
 
 	// parse units
 	r.p.eatWs()
-	unit := r.p.consumeRE(`\w+`)
+	unit := r.p.consumeRE(`^[a-z]+`)
+	units := append(getDurationUnits(), getShortUnits()...)
 	if unit == "" {
-		err = r.fail("")
+		err = r.fail("", units...)
 		return
 	}
 	unitDuration, durationErr := r.mapDurationUnit(unit)
 	if durationErr != nil {
 		r.p.rollback(len(unit))
-		err = r.fail(durationErr.Error())
+		err = r.fail(durationErr.Error(), units...)
+		return
+	}
+	d, scaleErr := unitDuration.scale(n, den)
+	if scaleErr != nil {
+		r.p.rollbackAt(start)
+		err = r.fail(scaleErr.Error(), "number")
 		return
 	}
-	d = unitDuration * time.Duration(n)
 
-	// check for more "and X Y..."
+	// check for more "and X Y...", compact "1h30m" or spaced "1h 30m"
+	compact := r.p.peekAny(digits()) != ""
 	r.p.eatWs()
-	if r.p.expect("and") {
-		curPos := r.p.pos
-		extraDuration, extraErr := r.parseRelBound()
-		if extraErr != nil {
-			r.p.rollbackAt(curPos)
-			err = r.fail(extraErr.Error())
-			return
+	next := r.p.pos
+	and := !compact && r.p.expect("and")
+	if !compact && !and && r.p.peekAny(digits()) == "" {
+		return
+	}
+	extraDuration, extraErr := r.parseRelBound()
+	if extraErr != nil {
+		if !compact && !and { // a number after a space may be something else: "1 day 2 April 2022"
+			r.p.rollbackAt(next)
+			return d, nil
 		}
-		d = d + extraDuration
+		err = extraErr
+		return
+	}
+	if d, scaleErr = d.tryPlus(extraDuration); scaleErr != nil {
+		r.p.rollbackAt(start)
+		err = r.fail(scaleErr.Error(), "number")
 	}
-
 	return
 }
 
+// maxFractionDigits is the number of fractional digits a number may have, a nanosecond is 0.000000001 seconds
+const maxFractionDigits = 9
+
+// parseNumber parses a whole or a fractional number as a decimal fraction num/den: "90" or "1.5"
+func (r *Recognizer) parseNumber() (num, den int, err *ParseError) {
+	start := r.p.pos
+	text := r.p.consumeRE(`^\d+(\.\d+)?`)
+	if text == "" {
+		return 0, 0, r.fail("", "number")
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	num, intErr := strconv.Atoi(whole + fraction)
+	if intErr != nil || len(fraction) > maxFractionDigits {
+		r.p.rollbackAt(start)
+		reason := "number is too large"
+		if len(fraction) > maxFractionDigits {
+			reason = fmt.Sprintf("at most %d fractional digits are supported", maxFractionDigits)
+		}
+		return 0, 0, r.fail(reason, "number")
+	}
+	return num, int(math.Pow10(len(fraction))), nil
+}
+
+// digits returns the alternatives which start a number
+func digits() []string {
+	return []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+}
+
 // parseRelnBound checks that text contains relative specification like "next month" or an interval like "2 days ago"
-func (r *Recognizer) parseRelnBound() (bound boundRelativeToNow, err error) {
+func (r *Recognizer) parseRelnBound() (bound boundRelativeToNow, err *ParseError) {
+	// check date math "now-7d/d"
+	if r.p.peekAny([]string{"now-", "now+", "now/"}) != "" {
+		r.p.expect("now")
+		ops, round, mathErr := parseDateMathOps(r.p)
+		if mathErr != nil {
+			err = mathErr
+			return
+		}
+		bound = *makeDateMathBound(ops, round)
+		return
+	}
+
+	// check numbered periods "2022-W23", "week 23 of 2022" or "Q1 2022"
+	if bound, err = r.parseNumberedPeriod(); err != nil || bound.year != 0 {
+		return
+	}
+
 	// check one-word onewords
 	verbalKeyword := r.p.expectAny(getShortWords())
 	if verbalKeyword != "" {
 		bound.verbal = verbalKeyword
+		if isDayPeriod(bound.verbal) {
+			err = r.parseTimeOfDay(&bound)
+		}
+		return
+	}
+
+	// check the current period "this week"
+	if r.p.expect("this") {
+		r.p.eatWs()
+		unit := r.p.consumeRE(`^[a-z]+`)
+		if bound.verbal = unitWord(unit); bound.verbal == "" {
+			r.p.rollback(len(unit))
+			err = r.fail("", getUnitWords()...)
+			return
+		}
+		bound.this = true
 		return
 	}
 
@@ -209,36 +419,260 @@ func (r *Recognizer) parseRelnBound() (bound boundRelativeToNow, err error) {
 			inFuture = true
 		}
 
+		// check several units "last 3 weeks", "next 2 full months", "last 7d"
+		if r.p.peekAny(append(digits(), "full ")) != "" {
+			bound, err = r.parseUnitsBound()
+			bound.inFuture = inFuture
+			return
+		}
+
 		// check interval keywords
 		verbal := r.p.expectAny(getPeriodWords())
-		if verbal != "" {
-			bound.inFuture = inFuture
-			bound.verbal = verbal
+		if verbal == "" {
+			err = r.fail("", getPeriodWords()...)
 			return
 		}
+		bound.inFuture = inFuture
+		bound.verbal = verbal
+		if isDayPeriod(bound.verbal) {
+			err = r.parseTimeOfDay(&bound)
+		}
+		return
 	}
 
 	// check intervals "X Y ago" or "X Y after"
+	keyword, offsetErr := r.parseOffset(&bound)
+	if offsetErr != nil {
+		err = offsetErr
+		return
+	}
+
+	// check anchors "3 days before last monday"
+	if keyword == "before" || keyword == "after" {
+		bound.anchor, err = r.parseAnchor()
+	}
+	return
+}
+
+// parseOffset parses an offset from now without an anchor: "X Y ago" or "X Y after", it returns the keyword
+func (r *Recognizer) parseOffset(bound *boundRelativeToNow) (keyword string, err *ParseError) {
+	startPos := r.p.pos
 	duration, durationErr := r.parseRelBound()
-	if durationErr == nil {
+	if durationErr != nil {
+		alts := append(getShortWords(), "last", "next")
+		err = durationErr.merge(newParseError(r.p.original, startPos, "", alts...))
+		return
+	}
+
+	r.p.eatWs()
+	keywords := []string{"ago", "before", "after", "later", "ahead"}
+	keyword = r.p.expectAny(keywords)
+	if keyword == "" {
+		err = r.fail("", keywords...)
+		return
+	}
+	bound.inFuture = keyword == "after" || keyword == "later" || keyword == "ahead"
+	bound.duration = duration
+	return
+}
+
+// parseAnchor parses the anchor of an offset: a bound relative to now or an absolute time after "before"/"after".
+// It returns nil if the bound ends after the keyword, so the offset is relative to now: "3 days before".
+func (r *Recognizer) parseAnchor() (*boundAnchor, *ParseError) {
+	if r.p.isEof() || r.p.peekAny(r.anchorTerminators()) != "" {
+		return nil, nil
+	}
+	r.p.eatWs()
+	anchorStart := r.p.pos
+	relN, relnErr := r.parseRelnBound()
+	if relnErr == nil {
+		return &boundAnchor{relN: &relN}, nil
+	}
+	r.p.rollbackAt(anchorStart)
+
+	text, _ := r.p.consumeUntil(r.anchorTerminators())
+	text = strings.Trim(text, " \n\t")
+	if t, err := dateparse.ParseStrict(text); err == nil && text != "" {
+		r.absTimes = append(r.absTimes, parsedTime{text, &t})
+		return &boundAnchor{abs: &t}, nil
+	}
+	r.p.rollbackAt(anchorStart)
+	return nil, relnErr.merge(r.fail("", "date"))
+}
+
+// failedAhead returns true if the error is beyond the next token, so the bound can't be a shorter alternative:
+// "3 days" is not the bound in "3 days before <invalid anchor>"
+func (r *Recognizer) failedAhead(err *ParseError) bool {
+	pos := r.p.pos
+	defer r.p.rollbackAt(pos)
+	r.p.eatWs()
+	return err != nil && err.Offset > r.p.pos
+}
+
+// anchorTerminators returns alternatives which finish an absolute anchor in any bound
+func (r *Recognizer) anchorTerminators() []string {
+	return append([]string{" to ", " until ", " within "}, r.boundTerminators()...)
+}
+
+// parseTimeOfDay parses an optional time of day after a day: "at 9am", "14:00", "at 9:30:15 pm", "noon", "midnight",
+// "start of day" or "end of day". Hours are counted from 0 to 23 unless "am" or "pm" follows.
+func (r *Recognizer) parseTimeOfDay(bound *boundRelativeToNow) *ParseError {
+	start := r.p.pos
+	r.p.eatWs()
+	at := r.p.expect("at ")
+	r.p.eatWs()
+	clockStart := r.p.pos
+
+	if word := r.p.expectAny(timeOfDayWords()); word != "" {
+		bound.timeOfDay = getTimeOfDayWords()[word]
+		return nil
+	}
+	m := timeOfDayRE.FindStringSubmatch(r.p.getRemainder())
+	if m == nil || m[2] == "" && m[4] == "" { // a single number is not a time: "9" needs "am" or minutes
+		if at {
+			return r.fail("", append(timeOfDayWords(), "time of day")...)
+		}
+		r.p.rollbackAt(start)
+		return nil
+	}
+	r.p.pos += len(m[0])
+
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	second, _ := strconv.Atoi(m[3])
+	valid := hour <= 23 && minute <= 59 && second <= 59
+	if m[4] != "" {
+		valid = valid && hour >= 1 && hour <= 12
+		hour %= 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	}
+	if !valid {
+		r.p.rollbackAt(clockStart)
+		return r.fail("invalid time of day", "time of day")
+	}
+	bound.timeOfDay = fmt.Sprintf("%02d:%02d:%02d", hour, minute, second)
+	return nil
+}
+
+var timeOfDayRE = regexp.MustCompile(`^(\d{1,2})(?::(\d{2})(?::(\d{2}))?)?\s*(am|pm)?\b`)
+
+// timeOfDayWords returns words of a time of day sorted to be matched by the parser, see getTimeOfDayWords
+func timeOfDayWords() []string {
+	words := make([]string, 0, len(getTimeOfDayWords()))
+	for w := range getTimeOfDayWords() {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// parseNumberedPeriod parses a numbered period of a year. Weeks are written in ISO 8601 "2022-W23" (or "2022W23") or
+// in words "week 23 of 2022", quarters and halves are written as "2022-Q3" or "Q3 2022" and "2021-H2" or "H2 2021".
+// Quarters and halves can be fiscal: "fiscal Q1 2023". The bound is empty if there is no numbered period.
+func (r *Recognizer) parseNumberedPeriod() (bound boundRelativeToNow, err *ParseError) {
+	start := r.p.pos
+	if r.p.expect("fiscal") {
 		r.p.eatWs()
-		keywords := []string{"ago", "before", "after", "later", "ahead"}
-		keyword := r.p.expectAny(keywords)
-		if keyword == "" {
-			err = r.fail("expected ago or after at this point")
+		bound.fiscal = true
+	}
+
+	var unit, year, index string
+	if m := numberedPeriodRE.FindStringSubmatch(r.p.getRemainder()); m != nil {
+		year, unit, index = m[1], m[2], m[3]
+		r.p.pos += len(m[0])
+	} else if m = periodOfYearRE.FindStringSubmatch(r.p.getRemainder()); m != nil {
+		unit, index, year = m[1], m[2], m[3]
+		r.p.pos += len(m[0])
+	} else if m = weekOfYearRE.FindStringSubmatch(r.p.getRemainder()); m != nil {
+		unit, index, year = "w", m[1], m[2]
+		r.p.pos += len(m[0])
+	} else {
+		if bound.fiscal {
+			err = r.fail("", "quarter", "half")
+		}
+		return
+	}
+
+	bound.verbal = map[string]string{"w": "week", "q": "quarter", "h": "half"}[unit]
+	bound.year, _ = strconv.Atoi(year)
+	bound.index, _ = strconv.Atoi(index)
+	last := getNumberedPeriods()[bound.verbal]
+	switch {
+	case bound.fiscal && bound.verbal == "week":
+		r.p.rollbackAt(start)
+		err = r.fail("fiscal weeks are not supported", "quarter", "half")
+	case bound.index < 1 || bound.index > last:
+		r.p.rollbackAt(start)
+		err = r.fail(fmt.Sprintf("a %s number must be between 1 and %d", bound.verbal, last), bound.verbal+" number")
+	}
+	return
+}
+
+var (
+	numberedPeriodRE = regexp.MustCompile(`^(\d{4})-?([wqh])(\d{1,2})\b`)
+	periodOfYearRE   = regexp.MustCompile(`^([qh])(\d)\s+(\d{4})\b`)
+	weekOfYearRE     = regexp.MustCompile(`^week\s+(\d{1,2})\s+of\s+(\d{4})\b`)
+)
+
+// toDateWords returns period-to-date shortcuts sorted to be matched by the parser, see getToDateWords
+func toDateWords() []string {
+	words := make([]string, 0, len(getToDateWords()))
+	for w := range getToDateWords() {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// parseUnitsBound parses the number of units after "last" or "next": "3 weeks", "3 full weeks", "full week", "7d"
+func (r *Recognizer) parseUnitsBound() (bound boundRelativeToNow, err *ParseError) {
+	start := r.p.pos
+	bound.count = 1
+	den := 1
+	if r.p.peekAny(digits()) != "" {
+		if bound.count, den, err = r.parseNumber(); err != nil {
 			return
 		}
-		inFuture := false
-		if keyword == "after" || keyword == "later" || keyword == "ahead" {
-			inFuture = true
+		if bound.count == 0 {
+			r.p.rollbackAt(start)
+			err = r.fail("the number of units must be positive", "number")
+			return
 		}
+		r.p.eatWs()
+	}
+	if r.p.expect("full") {
+		bound.full = true
+		r.p.eatWs()
+	}
 
-		bound.inFuture = inFuture
-		bound.duration = duration
+	units := append(getUnitWords(), getShortUnits()...)
+	unit := r.p.consumeRE(`^[a-z]+`)
+	if bound.verbal = unitWord(unit); bound.verbal == "" {
+		r.p.rollback(len(unit))
+		err = r.fail("", units...)
 		return
 	}
 
-	err = r.fail(durationErr.Error())
+	// a fractional number of units is counted in smaller units: "last 1.5 hours" is "last 90 minutes"
+	if den > 1 {
+		span, scaleErr := mapUnitToPeriod(bound.verbal).scale(bound.count, den)
+		reason := "full units can't be fractional"
+		if scaleErr != nil {
+			reason = scaleErr.Error()
+		}
+		if scaleErr != nil || bound.full {
+			r.p.rollbackAt(start)
+			err = r.fail(reason, "number")
+			return
+		}
+		bound.count, bound.verbal = wholeUnits(span)
+	}
+	if _, overflowErr := mapUnitToPeriod(bound.verbal).TryTimes(bound.count); overflowErr != nil {
+		r.p.rollbackAt(start)
+		err = r.fail(overflowErr.Error(), "number")
+	}
 	return
 }
 
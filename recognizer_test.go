@@ -1,8 +1,10 @@
 package window
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,9 +24,46 @@ func Test_recognitionFail(t *testing.T) {
 		{"WITHIN 1 2d", "failed to recognize the left bound"},
 		{"WITHIN 1", "failed to recognize the left bound"},
 		{"3 days max", "failed to recognize the right bound"},
+		{"[yesterday to today", "failed to recognize the right bound"},
+		{"yesterday to today)", "failed to recognize the right bound"},
+		{"yesterday to today by", "failed to recognize the right bound"},
+		{"session gap", "failed to recognize the left bound"},
+		{"session gap 30 minutes to today", "failed to recognize the right bound"},
+		{"yesterday to today by 1", "failed to recognize the right bound"},
 		{"1 April 2022 to", "failed to recognize the right bound"},
 		{"1 April 2022 to ", "failed to recognize the right bound"},
 		{"1 minute and 1 ", "failed to recognize the left bound"},
+		{"within 1.5 months", "failed to recognize the left bound"},
+		{"within 1.5", "failed to recognize the left bound"},
+		{"within 1.h", "failed to recognize the left bound"},
+		{"last 0 days", "failed to recognize the left bound"},
+		{"last 3 mondays", "failed to recognize the left bound"},
+		{"next 3 full", "failed to recognize the left bound"},
+		{"last 1.5 full days", "failed to recognize the left bound"},
+		{"last 0.5 months", "failed to recognize the left bound"},
+		{"last 0.0 hours", "failed to recognize the left bound"},
+		{"from 3000000 hours ago to now", "failed to recognize the left bound"},
+		{"1.123456789123 hours ago", "failed to recognize the left bound"},
+		{"0.00000000000000000001 seconds ago", "failed to recognize the left bound"},
+		{"99999999999999999999 seconds", "failed to recognize the left bound"},
+		{"5000000 hours and 5000000 hours", "failed to recognize the left bound"},
+		{"last 9999999999999999 hours", "failed to recognize the left bound"},
+		{"this fortnight", "failed to recognize the left bound"},
+		{"2022-W54", "failed to recognize the left bound"},
+		{"2022-Q5", "failed to recognize the left bound"},
+		{"from yesterday at 25:00 to now", "failed to recognize the left bound"},
+		{"from yesterday at 13pm to now", "failed to recognize the left bound"},
+		{"from yesterday at to now", "failed to recognize the left bound"},
+		{"from today to today 5", "failed to recognize the right bound"},
+		{"from 3 days before last fortnight", "failed to recognize the left bound"},
+		{"from yesterday to today in Mars/Olympus", "failed to recognize the right bound"},
+		{"from yesterday to today tz=europe/berlin", "failed to recognize the right bound"},
+		{"from yesterday to today in", "failed to recognize the right bound"},
+		{"from H3 2022", "failed to recognize the left bound"},
+		{"from fiscal 2022-W01", "failed to recognize the left bound"},
+		{"from fiscal year", "failed to recognize the left bound"},
+		{"from 2022-W1 to week 0 of 2022", "failed to recognize the right bound"},
+		{"MTD to now", "failed to recognize the right bound"},
 	}
 
 	for i, tt := range tests {
@@ -57,11 +96,11 @@ func Test_recognitionSuccess(t *testing.T) {
 		// 2. Abs-Rel
 		{"1 Jan 1991 within 1 day", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Jan 1991")
-			return makeSpecification(d1, 1*24*time.Hour)
+			return makeSpecification(d1, Period{Days: 1})
 		}},
 		{"1 April 2022 within 1 day", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Apr 2022")
-			return makeSpecification(d1, 1*24*time.Hour)
+			return makeSpecification(d1, Period{Days: 1})
 		}},
 		// 3. Abs-RelN
 		{"1 April 2022 to tomorrow", func() Specification {
@@ -74,12 +113,22 @@ func Test_recognitionSuccess(t *testing.T) {
 		}},
 		{"1 Jan 1991 to 1 day ahead", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Jan 1991")
-			return makeSpecification(d1, boundRelativeToNow{inFuture: true, duration: 24 * time.Hour})
+			return makeSpecification(d1, boundRelativeToNow{inFuture: true, duration: Period{Days: 1}})
 		}},
 		{"1 Jan 1991 until now", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Jan 1991")
 			return makeSpecification(d1, boundRelativeToNow{verbal: "now"})
 		}},
+		{"from last monday to today", func() Specification {
+			return makeSpecification(
+				boundRelativeToNow{inFuture: false, verbal: "monday"},
+				boundRelativeToNow{verbal: "today"},
+			)
+		}},
+		{"1 Jan 1991 to next june", func() Specification {
+			d1, _ := dateparse.ParseStrict("1 Jan 1991")
+			return makeSpecification(d1, boundRelativeToNow{inFuture: true, verbal: "june"})
+		}},
 		// 4. Rel-Abs
 		{"yesterday to 1 Apr 2022", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Apr 2022")
@@ -87,43 +136,124 @@ func Test_recognitionSuccess(t *testing.T) {
 		}},
 		// 5. Rel-Rel (Sliding window)
 		{"3 days", func() Specification {
-			return makeSpecification(3*24*time.Hour, nil)
+			return makeSpecification(Period{Days: 3}, nil)
 		}},
 		{"Within 3 days", func() Specification {
-			return makeSpecification(3*24*time.Hour, nil)
+			return makeSpecification(Period{Days: 3}, nil)
+		}},
+		{"within 3 months", func() Specification {
+			return makeSpecification(Period{Months: 3}, nil)
+		}},
+		{"1 year and 1 quarter", func() Specification {
+			return makeSpecification(Period{Years: 1, Months: 3}, nil)
+		}},
+		// shorthand and fractional durations
+		{"within 7d", func() Specification {
+			return makeSpecification(Period{Days: 7}, nil)
+		}},
+		{"1h30m", func() Specification {
+			return makeSpecification(Period{Duration: 90 * time.Minute}, nil)
+		}},
+		{"2mo1w", func() Specification {
+			return makeSpecification(Period{Months: 2, Days: 7}, nil)
+		}},
+		{"1y2mo3d4h5m6s7ms8us9ns", func() Specification {
+			return makeSpecification(Period{Years: 1, Months: 2, Days: 3, Duration: 4*time.Hour + 5*time.Minute + 6*time.Second + 7*time.Millisecond + 8*time.Microsecond + 9}, nil)
+		}},
+		{"within 1.5 hours", func() Specification {
+			return makeSpecification(Period{Duration: 90 * time.Minute}, nil)
+		}},
+		{"1.5d and 0.5 w", func() Specification {
+			return makeSpecification(Period{Days: 4, Duration: 24 * time.Hour}, nil)
+		}},
+		{"1.5 years", func() Specification {
+			return makeSpecification(Period{Years: 1, Months: 6}, nil)
+		}},
+		{"1h 30m ago to 2 d 12 h later", func() Specification {
+			return makeSpecification(boundRelativeToNow{duration: Period{Duration: 90 * time.Minute}}, boundRelativeToNow{inFuture: true, duration: Period{Days: 2, Duration: 12 * time.Hour}})
+		}},
+		{"1.000000001 seconds", func() Specification {
+			return makeSpecification(Period{Duration: time.Second + 1}, nil)
+		}},
+		{"90s ago to 2 h later", func() Specification {
+			return makeSpecification(boundRelativeToNow{duration: Period{Duration: 90 * time.Second}}, boundRelativeToNow{inFuture: true, duration: Period{Duration: 2 * time.Hour}})
 		}},
 		// 6. Rel-RelN
 		{"3 days until yesterday", func() Specification {
-			return makeSpecification(3*24*time.Hour, boundRelativeToNow{verbal: "yesterday"})
+			return makeSpecification(Period{Days: 3}, boundRelativeToNow{verbal: "yesterday"})
 		}},
 		{"3 days until last year", func() Specification {
-			return makeSpecification(3*24*time.Hour, boundRelativeToNow{inFuture: false, verbal: "year"})
+			return makeSpecification(Period{Days: 3}, boundRelativeToNow{inFuture: false, verbal: "year"})
 		}},
 		{"30 days until 2 days ago", func() Specification {
 			return makeSpecification(
-				30*24*time.Hour,
-				boundRelativeToNow{inFuture: false, duration: 2 * 24 * time.Hour},
+				Period{Days: 30},
+				boundRelativeToNow{inFuture: false, duration: Period{Days: 2}},
+			)
+		}},
+		{"1 month until 1 year ago", func() Specification {
+			return makeSpecification(
+				Period{Months: 1},
+				boundRelativeToNow{inFuture: false, duration: Period{Years: 1}},
 			)
 		}},
 		// 7. RelN-Abs
 		{"30 days to 1 Apr 2022", func() Specification {
 			d1, _ := dateparse.ParseStrict("1 Apr 2022")
-			return makeSpecification(30*24*time.Hour, d1)
+			return makeSpecification(Period{Days: 30}, d1)
 		}},
 		// 8. RelN-Rel
 		{"yesterday within 30 days", func() Specification {
-			return makeSpecification(boundRelativeToNow{verbal: "yesterday"}, 30*24*time.Hour)
+			return makeSpecification(boundRelativeToNow{verbal: "yesterday"}, Period{Days: 30})
 		}},
 		{"next year within 3 days and 2 hours", func() Specification {
-			return makeSpecification(boundRelativeToNow{inFuture: true, verbal: "year"}, 3*24*time.Hour+2*time.Hour)
+			return makeSpecification(boundRelativeToNow{inFuture: true, verbal: "year"}, Period{Days: 3, Duration: 2 * time.Hour})
 		}},
 		// 9. RelN-RelN
 		{"from last month until 2 hours later", func() Specification {
 			return makeSpecification(
 				boundRelativeToNow{inFuture: false, verbal: "month"},
-				boundRelativeToNow{inFuture: true, duration: 2 * time.Hour},
+				boundRelativeToNow{inFuture: true, duration: Period{Duration: 2 * time.Hour}},
 			)
 		}},
+		// bounds inclusivity
+		{"[from yesterday to today)", func() Specification {
+			s := makeSpecification(boundRelativeToNow{verbal: "yesterday"}, boundRelativeToNow{verbal: "today"})
+			s.rightExcluded = true
+			return s
+		}},
+		{"(1 Jan 1991 within 1 day]", func() Specification {
+			d1, _ := dateparse.ParseStrict("1 Jan 1991")
+			s := makeSpecification(d1, Period{Days: 1})
+			s.leftExcluded = true
+			return s
+		}},
+		// step
+		{"from last week to today by 1 hour", func() Specification {
+			s := makeSpecification(boundRelativeToNow{verbal: "week"}, boundRelativeToNow{verbal: "today"})
+			s.step = &Period{Duration: time.Hour}
+			return s
+		}},
+		{"[1 Jan 1991 to 2 Feb 1992) every 1 month", func() Specification {
+			d1, _ := dateparse.ParseStrict("1 Jan 1991")
+			d2, _ := dateparse.ParseStrict("2 Feb 1992")
+			s := makeSpecification(d1, d2)
+			s.rightExcluded = true
+			s.step = &Period{Months: 1}
+			return s
+		}},
+		{"1 Jan 1991 to 2 Feb 1992 by 6 hours", func() Specification {
+			d1, _ := dateparse.ParseStrict("1 Jan 1991")
+			d2, _ := dateparse.ParseStrict("2 Feb 1992")
+			s := makeSpecification(d1, d2)
+			s.step = &Period{Duration: 6 * time.Hour}
+			return s
+		}},
+		{"30 days by 1 day", func() Specification {
+			s := makeSpecification(Period{Days: 30}, nil)
+			s.step = &Period{Days: 1}
+			return s
+		}},
 	}
 
 	for i, tt := range tests {
@@ -139,3 +269,50 @@ func Test_recognitionSuccess(t *testing.T) {
 
 	}
 }
+
+func Test_parseError(t *testing.T) {
+	type test struct {
+		text                 string
+		offset, line, column int
+		token                string
+		expected             string // one of the expected alternatives
+		side                 BoundSide
+		caret                string
+	}
+	tests := []test{
+		{"WITHIN 1 2d", 9, 1, 10, "2d", "days", SideLeft, "WITHIN 1 2d\n         ^"},
+		{"3 days max", 7, 1, 8, "max", "yesterday", SideRight, "3 days max\n       ^"},
+		{"2 days sooner", 7, 1, 8, "sooner", "number", SideRight, "2 days sooner\n       ^"},
+		{"last fortnight", 5, 1, 6, "fortnight", "week", SideLeft, "last fortnight\n     ^"},
+		{"yesterday to today xyz", 19, 1, 20, "xyz", "end of text", SideRight, "yesterday to today xyz\n                   ^"},
+		{"from 1 day\nto tomorow", 14, 2, 4, "tomorow", "tomorrow", SideRight, "to tomorow\n   ^"},
+		{"1 minute and 1 ", 15, 1, 16, "", "seconds", SideLeft, "1 minute and 1 \n               ^"},
+		{"from 3000000 hours ago to now", 5, 1, 6, "3000000", "number", SideLeft, "from 3000000 hours ago to now\n     ^"},
+		{"from 3 days before garbage to now", 19, 1, 20, "garbage", "date", SideLeft, "from 3 days before garbage to now\n                   ^"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			_, err := Start(tt.text)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("error [%v] should be a ParseError", err)
+			}
+			if perr.Offset != tt.offset || perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("position [%d %d:%d] should be [%d %d:%d]", perr.Offset, perr.Line, perr.Column, tt.offset, tt.line, tt.column)
+			}
+			if perr.Token != tt.token {
+				t.Errorf("token [%s] should be [%s]", perr.Token, tt.token)
+			}
+			if !strings.Contains(strings.Join(perr.Expected, ","), tt.expected) {
+				t.Errorf("expected alternatives %v should contain [%s]", perr.Expected, tt.expected)
+			}
+			if perr.Side != tt.side {
+				t.Errorf("side [%s] should be [%s]", perr.Side, tt.side)
+			}
+			if !strings.HasSuffix(perr.Error(), "\n"+tt.caret) {
+				t.Errorf("error [%s] should end with [%s]", perr.Error(), tt.caret)
+			}
+		})
+	}
+}
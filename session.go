@@ -0,0 +1,96 @@
+package window
+
+import (
+	"sort"
+	"time"
+)
+
+// GetSessionGap return the gap of a session window ("session gap 30 minutes")
+func (s *Specification) GetSessionGap() (gap Period, ok bool) {
+	if s.sessionGap == nil {
+		return Period{}, false
+	}
+	return *s.sessionGap, true
+}
+
+// SessionWindower groups event timestamps into sessions: a session lasts while events come closer than the gap.
+// Events may come out of order within the allowed lateness, i.e. not older than the latest seen event minus the
+// lateness. A session is closed once no acceptable event can join it.
+// Emitted sessions are closed windows [first event, last event].
+type SessionWindower struct {
+	gap      Period
+	lateness time.Duration
+
+	watermark time.Time // events before it are too late
+	sessions  []session // open sessions ordered by time
+}
+
+type session struct {
+	first, last time.Time
+}
+
+// NewSessionWindower makes a windower for the gap, use zero lateness for sorted streams
+func NewSessionWindower(gap Period, lateness time.Duration) (*SessionWindower, error) {
+	if !isPositive(gap) || lateness < 0 {
+		return nil, ErrInvalidStep
+	}
+	return &SessionWindower{gap: gap, lateness: lateness}, nil
+}
+
+// SessionWindower makes a windower from a session specification: "session gap 30 minutes"
+func (s *Specification) SessionWindower(lateness time.Duration) (*SessionWindower, error) {
+	if s.sessionGap == nil {
+		return nil, ErrNotSession
+	}
+	return NewSessionWindower(*s.sessionGap, lateness)
+}
+
+// Add puts the event to a session and returns sessions which are closed after that.
+// It returns ErrLateEvent if the event is older than the lateness allows.
+func (sw *SessionWindower) Add(t time.Time) (closed []*Window, err error) {
+	if t.Before(sw.watermark) {
+		return nil, ErrLateEvent
+	}
+
+	// merge all sessions the event joins
+	joined := session{t, t}
+	open := sw.sessions[:0]
+	for _, s := range sw.sessions {
+		if sw.gap.AddTo(t).After(s.first) && sw.gap.AddTo(s.last).After(t) {
+			if s.first.Before(joined.first) {
+				joined.first = s.first
+			}
+			if s.last.After(joined.last) {
+				joined.last = s.last
+			}
+			continue
+		}
+		open = append(open, s)
+	}
+	sw.sessions = append(open, joined)
+	sort.Slice(sw.sessions, func(i, j int) bool { return sw.sessions[i].first.Before(sw.sessions[j].first) })
+
+	if watermark := t.Add(-sw.lateness); watermark.After(sw.watermark) {
+		sw.watermark = watermark
+	}
+	return sw.closeSessions(false), nil
+}
+
+// Flush closes all open sessions and returns them
+func (sw *SessionWindower) Flush() []*Window {
+	return sw.closeSessions(true)
+}
+
+// closeSessions removes sessions which can't be joined by acceptable events anymore
+func (sw *SessionWindower) closeSessions(all bool) (closed []*Window) {
+	open := sw.sessions[:0]
+	for _, s := range sw.sessions {
+		if all || !sw.gap.AddTo(s.last).After(sw.watermark) {
+			closed = append(closed, windowFromEdges(edge{t: s.first}, edge{t: s.last}))
+			continue
+		}
+		open = append(open, s)
+	}
+	sw.sessions = open
+	return closed
+}
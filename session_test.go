@@ -0,0 +1,106 @@
+package window
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func sessionsToString(windows []*Window) string {
+	return bucketsToString(windows, "15:04")
+}
+
+func TestSessionWindower_Sorted(t *testing.T) {
+	spec, err := Start("session gap 30 minutes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw, err := spec.SessionWindower(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type step struct {
+		event  string
+		closed string
+	}
+	steps := []step{
+		{"1 May 2022 10:00", ""},
+		{"1 May 2022 10:10", ""},
+		{"1 May 2022 10:39", ""},
+		{"1 May 2022 11:10", "[10:00,10:39]"},
+		{"1 May 2022 11:30", ""},
+		{"1 May 2022 12:00", "[11:10,11:30]"},
+	}
+	for _, s := range steps {
+		closed, err := sw.Add(dateparse.MustParse(s.event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := sessionsToString(closed); r != s.closed {
+			t.Errorf("after %s closed sessions [%s] should be [%s]", s.event, r, s.closed)
+		}
+	}
+
+	if _, err = sw.Add(dateparse.MustParse("1 May 2022 11:59")); !errors.Is(err, ErrLateEvent) {
+		t.Errorf("error [%v] should be [%v]", err, ErrLateEvent)
+	}
+	if r := sessionsToString(sw.Flush()); r != "[12:00,12:00]" {
+		t.Errorf("flushed sessions [%s] should be [12:00,12:00]", r)
+	}
+}
+
+func TestSessionWindower_Lateness(t *testing.T) {
+	sw, err := NewSessionWindower(Period{Duration: 30 * time.Minute}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type step struct {
+		event  string
+		closed string
+	}
+	steps := []step{
+		{"1 May 2022 10:00", ""},
+		{"1 May 2022 10:40", ""},
+		{"1 May 2022 10:25", ""}, // joins both sessions
+		{"1 May 2022 11:00", ""},
+		{"1 May 2022 10:50", ""},
+		{"1 May 2022 11:50", "[10:00,11:00]"},
+		{"1 May 2022 11:40", ""},
+		{"1 May 2022 13:00", "[11:40,11:50]"},
+	}
+	for _, s := range steps {
+		closed, err := sw.Add(dateparse.MustParse(s.event))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := sessionsToString(closed); r != s.closed {
+			t.Errorf("after %s closed sessions [%s] should be [%s]", s.event, r, s.closed)
+		}
+	}
+
+	if _, err = sw.Add(dateparse.MustParse("1 May 2022 12:44")); !errors.Is(err, ErrLateEvent) {
+		t.Errorf("error [%v] should be [%v]", err, ErrLateEvent)
+	}
+}
+
+func TestSessionSpecification(t *testing.T) {
+	spec, err := Start("session gap 1 hour and 30 minutes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gap, ok := spec.GetSessionGap(); !ok || gap != (Period{Duration: 90 * time.Minute}) {
+		t.Errorf("unexpected gap %v", gap)
+	}
+	if _, err = spec.TryResolveAt(time.Now()); !errors.Is(err, ErrSessionHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSessionHasNoBounds)
+	}
+
+	spec, _ = Start("yesterday to today")
+	if _, err = spec.SessionWindower(0); !errors.Is(err, ErrNotSession) {
+		t.Errorf("error [%v] should be [%v]", err, ErrNotSession)
+	}
+}
@@ -0,0 +1,224 @@
+package window
+
+import (
+	"sort"
+	"time"
+)
+
+// edge is a window bound with its inclusivity
+type edge struct {
+	t        time.Time
+	excluded bool
+}
+
+// startsBefore return true if the left edge a starts strictly before the left edge b
+func startsBefore(a, b edge) bool {
+	return a.t.Before(b.t) || a.t.Equal(b.t) && !a.excluded && b.excluded
+}
+
+// endsBefore return true if the right edge a ends strictly before the right edge b
+func endsBefore(a, b edge) bool {
+	return a.t.Before(b.t) || a.t.Equal(b.t) && a.excluded && !b.excluded
+}
+
+// isNonEmpty return true if there is at least one time point between the given left and right edges
+func isNonEmpty(from, to edge) bool {
+	return from.t.Before(to.t) || from.t.Equal(to.t) && !from.excluded && !to.excluded
+}
+
+// complement return the edge which continues the given one: the left edge for a right edge and vice versa
+// ex: the right edge "x)" is continued with the left edge "[x"
+func (e edge) complement() edge {
+	return edge{e.t, !e.excluded}
+}
+
+func windowFromEdges(from, to edge) *Window {
+	return &Window{from: &from.t, to: &to.t, fromExcluded: from.excluded, toExcluded: to.excluded}
+}
+
+// edges return bounds of the window, sliding windows have no bounds
+func (w *Window) edges() (from, to edge, err error) {
+	if w.from == nil || w.to == nil {
+		err = ErrSlidingHasNoBounds
+		return
+	}
+	return edge{*w.from, w.fromExcluded}, edge{*w.to, w.toExcluded}, nil
+}
+
+// Duration return the length of the window, for sliding windows it is the approximate slide (see GetSlide)
+func (w *Window) Duration() time.Duration {
+	if w.from == nil || w.to == nil {
+		return w.GetSlide()
+	}
+	return w.to.Sub(*w.from)
+}
+
+// equalTime return true if both times are nil or the same instant
+func equalTime(a, b *time.Time) bool {
+	return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
+}
+
+// Equal return true if both windows have the same bounds (with the same inclusivity) or the same slide
+func (w *Window) Equal(o *Window) bool {
+	return w.slide == o.slide &&
+		equalTime(w.from, o.from) && equalTime(w.to, o.to) &&
+		w.fromExcluded == o.fromExcluded && w.toExcluded == o.toExcluded
+}
+
+// Overlaps return true if there is a time point which belongs to both windows.
+// Sliding windows overlap nothing.
+func (w *Window) Overlaps(o *Window) bool {
+	i, err := w.Intersect(o)
+	return err == nil && i != nil
+}
+
+// ContainsWindow return true if every time point of the other window belongs to this window.
+// Sliding windows contain no windows.
+func (w *Window) ContainsWindow(o *Window) bool {
+	wFrom, wTo, err := w.edges()
+	if err != nil {
+		return false
+	}
+	oFrom, oTo, err := o.edges()
+	if err != nil {
+		return false
+	}
+	return !startsBefore(oFrom, wFrom) && !endsBefore(wTo, oTo)
+}
+
+// Intersect return the window made of time points which belong to both windows.
+// It returns nil if the windows do not overlap and ErrSlidingHasNoBounds if any of windows is sliding.
+func (w *Window) Intersect(o *Window) (*Window, error) {
+	wFrom, wTo, err := w.edges()
+	if err != nil {
+		return nil, err
+	}
+	oFrom, oTo, err := o.edges()
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := wFrom, wTo
+	if startsBefore(from, oFrom) {
+		from = oFrom
+	}
+	if endsBefore(oTo, to) {
+		to = oTo
+	}
+	if !isNonEmpty(from, to) {
+		return nil, nil
+	}
+	return windowFromEdges(from, to), nil
+}
+
+// Union return time points which belong to any of windows.
+// The result has one window if the windows overlap or adjoin, otherwise two.
+func (w *Window) Union(o *Window) (WindowSet, error) {
+	return WindowSet{w}.Union(o)
+}
+
+// Subtract return time points of the window which do not belong to the other window.
+// The result is empty if the other window covers this one and has two windows if it splits this one.
+func (w *Window) Subtract(o *Window) (WindowSet, error) {
+	i, err := w.Intersect(o)
+	if err != nil {
+		return nil, err
+	}
+	if i == nil {
+		return WindowSet{w}, nil
+	}
+
+	wFrom, wTo, _ := w.edges()
+	iFrom, iTo, _ := i.edges()
+	set := WindowSet{}
+	if leftTo := iFrom.complement(); isNonEmpty(wFrom, leftTo) {
+		set = append(set, windowFromEdges(wFrom, leftTo))
+	}
+	if rightFrom := iTo.complement(); isNonEmpty(rightFrom, wTo) {
+		set = append(set, windowFromEdges(rightFrom, wTo))
+	}
+	return set, nil
+}
+
+// WindowSet is a union of non-overlapping and non-adjoining windows ordered by time.
+// It is produced by set operations on windows when the result is not contiguous.
+type WindowSet []*Window
+
+// Contains return true if the time point belongs to any window of the set
+func (s WindowSet) Contains(t time.Time) bool {
+	for _, w := range s {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Duration return the total length of all windows in the set
+func (s WindowSet) Duration() (d time.Duration) {
+	for _, w := range s {
+		d += w.Duration()
+	}
+	return
+}
+
+// Union return the set extended with time points of the window
+func (s WindowSet) Union(w *Window) (WindowSet, error) {
+	all := append(WindowSet{w}, s...)
+	edges := make([][2]edge, 0, len(all))
+	for _, member := range all {
+		from, to, err := member.edges()
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, [2]edge{from, to})
+	}
+	sort.Slice(edges, func(i, j int) bool { return startsBefore(edges[i][0], edges[j][0]) })
+
+	// merge overlapping and adjoining windows
+	merged := [][2]edge{edges[0]}
+	for _, e := range edges[1:] {
+		last := &merged[len(merged)-1]
+		if startsBefore(last[1].complement(), e[0]) {
+			merged = append(merged, e) // there is a gap between windows
+			continue
+		}
+		if endsBefore(last[1], e[1]) {
+			last[1] = e[1]
+		}
+	}
+
+	set := make(WindowSet, 0, len(merged))
+	for _, e := range merged {
+		set = append(set, windowFromEdges(e[0], e[1]))
+	}
+	return set, nil
+}
+
+// Subtract return the set without time points of the window
+func (s WindowSet) Subtract(w *Window) (WindowSet, error) {
+	set := WindowSet{}
+	for _, member := range s {
+		rest, err := member.Subtract(w)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, rest...)
+	}
+	return set, nil
+}
+
+// Intersect return time points of the set which belong to the window
+func (s WindowSet) Intersect(w *Window) (WindowSet, error) {
+	set := WindowSet{}
+	for _, member := range s {
+		i, err := member.Intersect(w)
+		if err != nil {
+			return nil, err
+		}
+		if i != nil {
+			set = append(set, i)
+		}
+	}
+	return set, nil
+}
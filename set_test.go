@@ -0,0 +1,219 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+func resolveWindow(t *testing.T, text string) *Window {
+	t.Helper()
+	spec, err := Start(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := spec.TryResolveAt(dateparse.MustParse("1 May 2022 00:00:00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func setToString(s WindowSet) string {
+	parts := make([]string, 0, len(s))
+	for _, w := range s {
+		parts = append(parts, windowToString(w))
+	}
+	return strings.Join(parts, " ")
+}
+
+func windowToString(w *Window) string {
+	if w == nil {
+		return "nil"
+	}
+	l, r := "[", "]"
+	if w.fromExcluded {
+		l = "("
+	}
+	if w.toExcluded {
+		r = ")"
+	}
+	return fmt.Sprintf("%s%s,%s%s", l, w.from.Format("2 Jan"), w.to.Format("2 Jan"), r)
+}
+
+func TestWindow_Intersect(t *testing.T) {
+	type test struct {
+		a, b, result string
+	}
+	tests := []test{
+		{"1 Apr 2022 to 5 Apr 2022", "3 Apr 2022 to 7 Apr 2022", "[3 Apr,5 Apr]"},
+		{"1 Apr 2022 to 5 Apr 2022", "2 Apr 2022 to 3 Apr 2022", "[2 Apr,3 Apr]"},
+		{"[1 Apr 2022 to 5 Apr 2022)", "(3 Apr 2022 to 7 Apr 2022]", "(3 Apr,5 Apr)"},
+		{"1 Apr 2022 to 5 Apr 2022", "5 Apr 2022 to 7 Apr 2022", "[5 Apr,5 Apr]"},
+		{"[1 Apr 2022 to 5 Apr 2022)", "5 Apr 2022 to 7 Apr 2022", "nil"},
+		{"1 Apr 2022 to 2 Apr 2022", "5 Apr 2022 to 7 Apr 2022", "nil"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			a, b := resolveWindow(t, tt.a), resolveWindow(t, tt.b)
+			w, err := a.Intersect(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := windowToString(w); r != tt.result {
+				t.Errorf("intersection [%s] should be [%s]", r, tt.result)
+			}
+			if a.Overlaps(b) != (tt.result != "nil") || b.Overlaps(a) != (tt.result != "nil") {
+				t.Errorf("overlapping is not consistent with the intersection")
+			}
+		})
+	}
+}
+
+func TestWindow_Union(t *testing.T) {
+	type test struct {
+		a, b, result string
+	}
+	tests := []test{
+		{"1 Apr 2022 to 5 Apr 2022", "3 Apr 2022 to 7 Apr 2022", "[1 Apr,7 Apr]"},
+		{"3 Apr 2022 to 7 Apr 2022", "1 Apr 2022 to 5 Apr 2022", "[1 Apr,7 Apr]"},
+		{"[1 Apr 2022 to 5 Apr 2022)", "[5 Apr 2022 to 7 Apr 2022)", "[1 Apr,7 Apr)"},
+		{"[1 Apr 2022 to 5 Apr 2022)", "(5 Apr 2022 to 7 Apr 2022)", "[1 Apr,5 Apr) (5 Apr,7 Apr)"},
+		{"1 Apr 2022 to 2 Apr 2022", "5 Apr 2022 to 7 Apr 2022", "[1 Apr,2 Apr] [5 Apr,7 Apr]"},
+		{"1 Apr 2022 to 7 Apr 2022", "(2 Apr 2022 to 3 Apr 2022)", "[1 Apr,7 Apr]"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			set, err := resolveWindow(t, tt.a).Union(resolveWindow(t, tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := setToString(set); r != tt.result {
+				t.Errorf("union [%s] should be [%s]", r, tt.result)
+			}
+		})
+	}
+}
+
+func TestWindow_Subtract(t *testing.T) {
+	type test struct {
+		a, b, result string
+	}
+	tests := []test{
+		{"1 Apr 2022 to 5 Apr 2022", "3 Apr 2022 to 7 Apr 2022", "[1 Apr,3 Apr)"},
+		{"3 Apr 2022 to 7 Apr 2022", "1 Apr 2022 to 5 Apr 2022", "(5 Apr,7 Apr]"},
+		{"1 Apr 2022 to 7 Apr 2022", "[2 Apr 2022 to 3 Apr 2022)", "[1 Apr,2 Apr) [3 Apr,7 Apr]"},
+		{"2 Apr 2022 to 3 Apr 2022", "1 Apr 2022 to 7 Apr 2022", ""},
+		{"1 Apr 2022 to 2 Apr 2022", "5 Apr 2022 to 7 Apr 2022", "[1 Apr,2 Apr]"},
+		{"1 Apr 2022 to 5 Apr 2022", "(1 Apr 2022 to 5 Apr 2022)", "[1 Apr,1 Apr] [5 Apr,5 Apr]"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			set, err := resolveWindow(t, tt.a).Subtract(resolveWindow(t, tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := setToString(set); r != tt.result {
+				t.Errorf("difference [%s] should be [%s]", r, tt.result)
+			}
+		})
+	}
+}
+
+func TestWindow_ContainsWindow(t *testing.T) {
+	type test struct {
+		a, b     string
+		contains bool
+	}
+	tests := []test{
+		{"1 Apr 2022 to 5 Apr 2022", "2 Apr 2022 to 3 Apr 2022", true},
+		{"1 Apr 2022 to 5 Apr 2022", "1 Apr 2022 to 5 Apr 2022", true},
+		{"[1 Apr 2022 to 5 Apr 2022)", "1 Apr 2022 to 5 Apr 2022", false},
+		{"1 Apr 2022 to 5 Apr 2022", "(1 Apr 2022 to 5 Apr 2022)", true},
+		{"1 Apr 2022 to 5 Apr 2022", "3 Apr 2022 to 7 Apr 2022", false},
+		{"30 days", "1 Apr 2022 to 5 Apr 2022", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			if c := resolveWindow(t, tt.a).ContainsWindow(resolveWindow(t, tt.b)); c != tt.contains {
+				t.Errorf("containment [%t] should be [%t]", c, tt.contains)
+			}
+		})
+	}
+}
+
+func TestWindow_Equal(t *testing.T) {
+	a := resolveWindow(t, "1 Apr 2022 to 5 Apr 2022")
+	if !a.Equal(resolveWindow(t, "1 Apr 2022 within 4 days")) {
+		t.Errorf("windows should be equal")
+	}
+	if a.Equal(resolveWindow(t, "[1 Apr 2022 to 5 Apr 2022)")) {
+		t.Errorf("windows with different inclusivity should not be equal")
+	}
+	if !resolveWindow(t, "30 days").Equal(resolveWindow(t, "within 30 days")) {
+		t.Errorf("sliding windows should be equal")
+	}
+}
+
+func TestWindow_Duration(t *testing.T) {
+	if d := resolveWindow(t, "1 Apr 2022 to 5 Apr 2022").Duration(); d != 4*24*time.Hour {
+		t.Errorf("duration [%s] should be 96h", d)
+	}
+	if d := resolveWindow(t, "2 hours").Duration(); d != 2*time.Hour {
+		t.Errorf("duration [%s] should be 2h", d)
+	}
+	set, _ := resolveWindow(t, "1 Apr 2022 to 7 Apr 2022").Subtract(resolveWindow(t, "2 Apr 2022 to 3 Apr 2022"))
+	if d := set.Duration(); d != 5*24*time.Hour {
+		t.Errorf("duration [%s] should be 120h", d)
+	}
+}
+
+func TestWindow_SlidingSetOperations(t *testing.T) {
+	sliding, bounded := resolveWindow(t, "30 days"), resolveWindow(t, "1 Apr 2022 to 5 Apr 2022")
+	if _, err := sliding.Intersect(bounded); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+	if _, err := bounded.Union(sliding); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+	if _, err := bounded.Subtract(sliding); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+	if sliding.Overlaps(bounded) {
+		t.Errorf("sliding windows should not overlap")
+	}
+}
+
+func TestWindowSet(t *testing.T) {
+	set, err := WindowSet{}.Union(resolveWindow(t, "1 Apr 2022 to 2 Apr 2022"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, _ = set.Union(resolveWindow(t, "5 Apr 2022 to 7 Apr 2022"))
+	set, _ = set.Union(resolveWindow(t, "10 Apr 2022 to 12 Apr 2022"))
+	set, _ = set.Union(resolveWindow(t, "2 Apr 2022 to 5 Apr 2022"))
+	if r := setToString(set); r != "[1 Apr,7 Apr] [10 Apr,12 Apr]" {
+		t.Errorf("unexpected union [%s]", r)
+	}
+
+	set, _ = set.Subtract(resolveWindow(t, "[3 Apr 2022 to 11 Apr 2022)"))
+	if r := setToString(set); r != "[1 Apr,3 Apr) [11 Apr,12 Apr]" {
+		t.Errorf("unexpected difference [%s]", r)
+	}
+	if !set.Contains(dateparse.MustParse("11 Apr 2022")) || set.Contains(dateparse.MustParse("3 Apr 2022")) {
+		t.Errorf("unexpected containment")
+	}
+
+	set, _ = set.Intersect(resolveWindow(t, "2 Apr 2022 to 11 Apr 2022"))
+	if r := setToString(set); r != "[2 Apr,3 Apr) [11 Apr,11 Apr]" {
+		t.Errorf("unexpected intersection [%s]", r)
+	}
+}
@@ -2,29 +2,83 @@ package window
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
-func mapUnitToDuration(unit string) (d time.Duration) {
+func mapUnitToPeriod(unit string) (p Period) {
 	switch unit {
-	case "nanosecond", "nanoseconds":
-		d = time.Nanosecond
-	case "microsecond", "microseconds":
-		d = time.Microsecond
-	case "millisecond", "milliseconds":
-		d = time.Millisecond
-	case "second", "seconds":
-		d = time.Second
-	case "minute", "minutes":
-		d = time.Minute
-	case "hour", "hours":
-		d = time.Hour
-	case "day", "days":
-		d = time.Hour * 24
-	case "week", "weeks":
-		d = time.Hour * 24 * 7
+	case "nanosecond", "nanoseconds", "ns":
+		p.Duration = time.Nanosecond
+	case "microsecond", "microseconds", "us":
+		p.Duration = time.Microsecond
+	case "millisecond", "milliseconds", "ms":
+		p.Duration = time.Millisecond
+	case "second", "seconds", "s":
+		p.Duration = time.Second
+	case "minute", "minutes", "m":
+		p.Duration = time.Minute
+	case "hour", "hours", "h":
+		p.Duration = time.Hour
+	case "day", "days", "d":
+		p.Days = 1
+	case "week", "weeks", "w":
+		p.Days = 7
+	case "month", "months", "mo":
+		p.Months = 1
+	case "quarter", "quarters":
+		p.Months = 3
+	case "half", "halves":
+		p.Months = 6
+	case "year", "years", "y":
+		p.Years = 1
+	}
+	return p
+}
+
+// mapWeekday converts a weekday name to time.Weekday
+func mapWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == name {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// mapMonth converts a month name to time.Month
+func mapMonth(name string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		if strings.ToLower(m.String()) == name {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// firstDayOfMonth returns the beginning of the month which contains t
+func firstDayOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// daysInMonth returns the number of days in the month which contains t
+func daysInMonth(t time.Time) int {
+	return firstDayOfMonth(t).AddDate(0, 1, -1).Day()
+}
+
+// getDurationUnits returns a list of units that can be used in durations
+// ex: "3 days"
+func getDurationUnits() []string {
+	return []string{
+		"nanosecond", "microsecond", "millisecond", "second", "minute", "hour", "day", "week", "month", "quarter", "year",
+		"nanoseconds", "microseconds", "milliseconds", "seconds", "minutes", "hours", "days", "weeks", "months", "quarters", "years",
 	}
-	return d
+}
+
+// getShortUnits returns a list of unit abbreviations that can be used in durations, "m" is a minute and "mo" is a month
+// ex: "7d", "1h30m"
+func getShortUnits() []string {
+	return []string{"ns", "us", "ms", "s", "m", "h", "d", "w", "mo", "y"}
 }
 
 // getPeriodWords returns a list of possible predefined words that can be used in the bound definition relative to now
@@ -33,153 +87,339 @@ func getPeriodWords() []string {
 	return []string{
 		"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
 		"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december",
-		"nanosecond", "microsecond", "millisecond", "second", "minute", "hour", "day", "week", "month", "year",
-		"nanoseconds", "microseconds", "milliseconds", "seconds", "minutes", "hour", "days", "weeks", "months", "years",
+		"nanosecond", "microsecond", "millisecond", "second", "minute", "hour", "day", "week", "month", "quarter", "half",
+		"year",
+		"nanoseconds", "microseconds", "milliseconds", "seconds", "minutes", "hours", "days", "weeks", "months", "quarters",
+		"halves", "years",
 	}
 }
 
+// getUnitWords returns a list of units that can be counted in the bound definition relative to now
+// ex: "last 3 weeks", "next 2 full months" or "this quarter"
+func getUnitWords() []string {
+	return []string{
+		"nanosecond", "microsecond", "millisecond", "second", "minute", "hour", "day", "week", "month", "quarter", "half",
+		"year",
+		"nanoseconds", "microseconds", "milliseconds", "seconds", "minutes", "hours", "days", "weeks", "months", "quarters",
+		"halves", "years",
+	}
+}
+
+// getNumberedPeriods returns the periods which are numbered within a year and the largest number of each
+// ex: "2022-W23", "Q1 2022" or "H2 2021"
+func getNumberedPeriods() map[string]int {
+	return map[string]int{"week": 53, "quarter": 4, "half": 2}
+}
+
+// getToDateWords returns a list of period-to-date shortcuts and the units of their periods, a shortcut makes both
+// bounds of a window: from the beginning of the current period to now
+// ex: "MTD" or "month to date"
+func getToDateWords() map[string]string {
+	return map[string]string{
+		"wtd": "week", "mtd": "month", "qtd": "quarter", "ytd": "year",
+		"week to date": "week", "month to date": "month", "quarter to date": "quarter", "year to date": "year",
+	}
+}
+
+// unitWord returns the singular unit word for a unit which can be counted: "weeks" and "w" are "week".
+// It returns an empty string for anything else.
+func unitWord(unit string) string {
+	p := mapUnitToPeriod(unit)
+	for _, w := range getUnitWords() {
+		if mapUnitToPeriod(w) == p && !strings.HasSuffix(w, "s") {
+			return w
+		}
+	}
+	return ""
+}
+
+// wholeUnits returns the period as a number of the largest unit which fits it whole: 18 months for 1.5 years,
+// 36 hours for 1 day and 12 hours. Days are counted as 24 hours.
+func wholeUnits(p Period) (count int, unit string) {
+	months := p.Years*12 + p.Months
+	clock := time.Duration(p.Days)*24*time.Hour + p.Duration
+	for _, w := range []string{"year", "month", "week", "day", "hour", "minute", "second", "millisecond", "microsecond"} {
+		u := mapUnitToPeriod(w)
+		switch {
+		case u.Years != 0 || u.Months != 0:
+			if p.Days == 0 && p.Duration == 0 && months%(u.Years*12+u.Months) == 0 {
+				return months / (u.Years*12 + u.Months), w
+			}
+		case u.Days != 0:
+			if months == 0 && p.Duration == 0 && p.Days%u.Days == 0 {
+				return p.Days / u.Days, w
+			}
+		case months == 0 && clock%u.Duration == 0:
+			return int(clock / u.Duration), w
+		}
+	}
+	return int(clock), "nanosecond"
+}
+
 // getShortWords returns a list of possible predefined words that can be used in the bound definition relative to now as is
 func getShortWords() []string {
 	return []string{"today", "yesterday", "now", "tomorrow"}
 }
 
+// getTimeOfDayWords returns a list of words which can be used as a time of day after a day and their clock times
+// ex: "yesterday noon" or "today end of day"
+func getTimeOfDayWords() map[string]string {
+	return map[string]string{"noon": "12:00:00", "midnight": "00:00:00", "start of day": "00:00:00", endOfDay: endOfDay}
+}
+
+const (
+	timeOfDayLayout = "15:04:05"   // the clock time of boundRelativeToNow.timeOfDay
+	endOfDay        = "end of day" // the time of day which is the end of the day, see atTimeOfDay
+)
+
+// isDayPeriod returns true for the verbals which are a single day and can be given a time of day
+// ex: "yesterday", "next day" or "last monday"
+func isDayPeriod(verbal string) bool {
+	if _, ok := mapWeekday(verbal); ok {
+		return true
+	}
+	switch verbal {
+	case "today", "yesterday", "tomorrow", "day", "days":
+		return true
+	}
+	return false
+}
+
+// atTimeOfDay returns the point of the day which starts at day, the time of day is taken on the wall clock.
+// The end of day is picked as the right edge of the day: the last nanosecond, or the next day start if the bound is
+// excluded.
+func atTimeOfDay(day time.Time, timeOfDay string, isExcluded bool) time.Time {
+	if timeOfDay == endOfDay {
+		return pickPeriodEdge(day, Period{Days: 1}, false, isExcluded)
+	}
+	clock, _ := time.Parse(timeOfDayLayout, timeOfDay)
+	y, m, d := day.Date()
+	return time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location())
+}
+
 // boundRelativeToNow contains a time specification relative to another point in time
 // ex: "yesterday", "last june", "next week", "2 days after"
 type boundRelativeToNow struct {
-	inFuture bool          // direction
-	verbal   string        // "june", "year", "week", "today", "yesterday"
-	duration time.Duration // "2 days", "1 second"
+	inFuture bool   // direction
+	verbal   string // "june", "year", "week", "today", "yesterday"
+	duration Period // "2 days", "1 second", "3 months"
+	round    string // the unit a point is rounded to in date math: "day" in "now-7d/d", see resolveRounding
+	count    int    // the number of units in "last 3 weeks", 0 for a single calendar period "last week"
+	full     bool   // "last 3 full weeks" are whole calendar weeks, otherwise the weeks are counted from now
+	this     bool   // "this week" is the calendar period which contains now
+	year     int    // the year of a numbered period: 2022 in "2022-W23"
+	index    int    // the number of a period in the year: 23 in "2022-W23"
+	fiscal   bool   // "fiscal Q1 2023" is numbered in the fiscal year, see Calendar.FiscalYearStart
+	// timeOfDay makes a point of a day period: "09:00:00" in "yesterday at 9am" or "end of day", see atTimeOfDay
+	timeOfDay string
+	// anchor is the point the duration is counted from instead of now: "last monday" in "3 days before last monday"
+	anchor *boundAnchor
+}
+
+// boundAnchor is the anchor of an offset, either absolute or relative to now, so offsets make an expression tree:
+// "1 hour after 2 days before last monday"
+type boundAnchor struct {
+	abs  *time.Time          // "2022-04-01"
+	relN *boundRelativeToNow // "last monday", "2 days ago"
+}
+
+// resolveAt returns the point of the anchor an offset is counted from: the beginning of a period for offsets before
+// the anchor and the end of the period (the next period start) for offsets after it
+func (a *boundAnchor) resolveAt(n time.Time, fromEnd bool, cal Calendar) time.Time {
+	if a.abs != nil {
+		return *a.abs
+	}
+	if a.relN.isWhole() {
+		from, to := a.relN.resolveWhole(n, fromEnd, cal)
+		if fromEnd {
+			return to
+		}
+		return from
+	}
+	// the start of a period is its left edge and the end is its right edge, see pickPeriodEdge
+	return a.relN.resolveAt(n, !fromEnd, fromEnd, cal)
 }
 
 // resolveAt map the relN bound to time. It uses isFuture/isLeftBound to understand which bound of the interval to pick.
 // -----[last year]------[NOW]------[next year]----
 //      ^		  ^					^		  ^   <---- possible picks depending on isLeftBound and isFuture
-func (b *boundRelativeToNow) resolveAt(n time.Time, isLeftBound bool) time.Time {
-	layout := "2006-01-02 15:04:05.000000000 MST"
-	tz := n.Format("MST")
-	var leftBoundString, rightBoundString string
+// A period is a half-open interval [start, next period start). When its right edge is picked for an excluded
+// window bound, the next period start is returned, otherwise the last nanosecond of the period.
+func (b *boundRelativeToNow) resolveAt(n time.Time, isLeftBound, isExcluded bool, cal Calendar) time.Time {
+	// a point: "2 days ago", "1 month later", "now-7d/d", or an offset from an anchor: "3 days before last monday",
+	// "2 hours after tomorrow", "now-1y-6M"
+	if b.verbal == "" {
+		at := n
+		if b.anchor != nil {
+			at = b.anchor.resolveAt(n, b.inFuture, cal)
+		}
+		return resolveRounding(b.offset().AddTo(at), b.round, isLeftBound, isExcluded, cal)
+	}
 
-	// verbal map
-	if b.verbal != "" {
-		sign := -1
-		if b.inFuture {
-			sign = 1
+	// a whole period gives its edges like any other period: "this week", "last 3 weeks", "Q1 2022", see resolveWhole
+	if b.isWhole() {
+		from, to := b.resolveWhole(n, isExcluded, cal)
+		if isLeftBound {
+			return from
 		}
+		return to
+	}
 
-		switch b.verbal {
-		case "now":
-			return n
-		case "today":
-			tomorrowString := n.Format("2006-01-02")
-			leftBoundString = fmt.Sprintf("%s  00:00:00.000000000 %s", tomorrowString, tz)
-			rightBoundString = fmt.Sprintf("%s 23:59:59.999999999 %s", tomorrowString, tz)
-		case "tomorrow":
-			tomorrowString := n.AddDate(0, 0, 1).Format("2006-01-02")
-			leftBoundString = fmt.Sprintf("%s  00:00:00.000000000 %s", tomorrowString, tz)
-			rightBoundString = fmt.Sprintf("%s 23:59:59.999999999 %s", tomorrowString, tz)
-		case "yesterday":
-			tomorrowString := n.AddDate(0, 0, -1).Format("2006-01-02")
-			leftBoundString = fmt.Sprintf("%s  00:00:00.000000000 %s", tomorrowString, tz)
-			rightBoundString = fmt.Sprintf("%s 23:59:59.999999999 %s", tomorrowString, tz)
-		case "nanosecond", "nanoseconds":
-			nanosecondString := n.Add(time.Duration(sign) * time.Nanosecond).Format("2006-01-02 15:04:05.999999999")
-			leftBoundString = fmt.Sprintf("%s %s", nanosecondString, tz)
-			rightBoundString = fmt.Sprintf("%s %s", nanosecondString, tz)
-		case "microsecond", "microseconds":
-			microsecondString := n.Add(time.Duration(sign) * time.Microsecond).Format("2006-01-02 15:04:05.999999")
-			leftBoundString = fmt.Sprintf("%s000 %s", microsecondString, tz)
-			rightBoundString = fmt.Sprintf("%s999 %s", microsecondString, tz)
-		case "millisecond", "milliseconds":
-			millisecondString := n.Add(time.Duration(sign) * time.Millisecond).Format("2006-01-02 15:04:05.999")
-			leftBoundString = fmt.Sprintf("%s000000 %s", millisecondString, tz)
-			rightBoundString = fmt.Sprintf("%s999999 %s", millisecondString, tz)
-		case "second", "seconds":
-			secondString := n.Add(time.Duration(sign) * time.Second).Format("2006-01-02 15:04:05")
-			leftBoundString = fmt.Sprintf("%s.000000000 %s", secondString, tz)
-			rightBoundString = fmt.Sprintf("%s.999999999 %s", secondString, tz)
-		case "minute", "minutes":
-			minuteString := n.Add(time.Duration(sign) * time.Minute).Format("2006-01-02 15:04")
-			leftBoundString = fmt.Sprintf("%s:00.000000000 %s", minuteString, tz)
-			rightBoundString = fmt.Sprintf("%s:59.999999999 %s", minuteString, tz)
-		case "hour", "hours":
-			hourString := n.Add(time.Duration(sign) * time.Hour).Format("2006-01-02 15")
-			leftBoundString = fmt.Sprintf("%s:00:00.000000000 %s", hourString, tz)
-			rightBoundString = fmt.Sprintf("%s:59:59.999999999 %s", hourString, tz)
-		case "day", "days":
-			dayString := n.AddDate(0, 0, sign*1).Format("2006-01-02")
-			leftBoundString = fmt.Sprintf("%s  00:00:00.000000000 %s", dayString, tz)
-			rightBoundString = fmt.Sprintf("%s 23:59:59.999999999 %s", dayString, tz)
-		case "week", "weeks":
-			var nextMonday time.Time
-			switch n.Weekday() {
-			case time.Monday:
-				nextMonday = n.AddDate(0, 0, 7)
-			case time.Tuesday:
-				nextMonday = n.AddDate(0, 0, 6)
-			case time.Wednesday:
-				nextMonday = n.AddDate(0, 0, 5)
-			case time.Thursday:
-				nextMonday = n.AddDate(0, 0, 4)
-			case time.Friday:
-				nextMonday = n.AddDate(0, 0, 3)
-			case time.Saturday:
-				nextMonday = n.AddDate(0, 0, 2)
-			case time.Sunday:
-				nextMonday = n.AddDate(0, 0, 1)
-			}
-			mondayString := nextMonday.Format("2006-01-02")
-			leftBoundString = fmt.Sprintf("%s  00:00:00.000000000 %s", mondayString, tz)
-			rightBoundString = fmt.Sprintf("%s 23:59:59.999999999 %s", mondayString, tz)
-		case "month", "months":
-			daysInMonth := map[string]uint8{
-				"1":  30,
-				"2":  31,
-				"3":  30,
-				"4":  31,
-				"5":  30,
-				"6":  31,
-				"7":  30,
-				"8":  31,
-				"9":  30,
-				"10": 31,
-				"11": 30,
-				"12": 31,
-			}
+	// verbal map
+	sign := -1
+	if b.inFuture {
+		sign = 1
+	}
+
+	// periods are computed from the wall clock in the location of now, so days last 23 or 25 hours around DST
+	// transitions, see Period.AddTo
+	y, m, d := n.Date()
+	loc := n.Location()
+	var start time.Time
+	length := mapUnitToPeriod(b.verbal)
 
-			d := n.AddDate(0, sign*1, 0)
-			days := daysInMonth[d.Format("M")]
-			monthString := n.AddDate(0, sign*1, 0).Format("2006-01")
-
-			leftBoundString = fmt.Sprintf("%s-01  00:00:00.000000000 %s", monthString, tz)
-			rightBoundString = fmt.Sprintf("%s-%d 23:59:59.999999999 %s", monthString, days, tz)
-		case "year", "years":
-			yearString := n.AddDate(sign*1, 0, 0).Format("2006")
-			leftBoundString = fmt.Sprintf("%s-01-01  00:00:00.000000000 %s", yearString, tz)
-			rightBoundString = fmt.Sprintf("%s-12-31 23:59:59.999999999 %s", yearString, tz)
-		default:
-			panic(fmt.Errorf("verbal [%s] not recognized", b.verbal))
+	switch b.verbal {
+	case "now":
+		return n
+	case "today":
+		start, length = time.Date(y, m, d, 0, 0, 0, 0, loc), Period{Days: 1}
+	case "tomorrow":
+		start, length = time.Date(y, m, d+1, 0, 0, 0, 0, loc), Period{Days: 1}
+	case "yesterday":
+		start, length = time.Date(y, m, d-1, 0, 0, 0, 0, loc), Period{Days: 1}
+	case "nanosecond", "nanoseconds", "microsecond", "microseconds", "millisecond", "milliseconds",
+		"second", "seconds", "minute", "minutes", "hour", "hours":
+		start = truncateWallClock(n.Add(time.Duration(sign)*length.Duration), length.Duration)
+	case "day", "days":
+		start = time.Date(y, m, d+sign, 0, 0, 0, 0, loc)
+	case "week", "weeks":
+		// the week before (after) the current one, weeks start on Calendar.WeekStart
+		start = cal.startOfWeek(n).AddDate(0, 0, sign*7)
+	case "month", "months":
+		start = time.Date(y, m+time.Month(sign), 1, 0, 0, 0, 0, loc)
+	case "quarter", "quarters", "half", "halves":
+		// calendar quarters (halves) start in January, April, July and October (January and July)
+		start = alignToStep(n, length, cal).AddDate(0, sign*length.Months, 0)
+	case "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday":
+		// the closest matching day strictly before (after) today
+		weekday, _ := mapWeekday(b.verbal)
+		start = time.Date(y, m, d+sign, 0, 0, 0, 0, loc)
+		for start.Weekday() != weekday {
+			start = start.AddDate(0, 0, sign)
+		}
+		length = Period{Days: 1}
+	case "january", "february", "march", "april", "may", "june", "july",
+		"august", "september", "october", "november", "december":
+		// the closest matching month strictly before (after) the current one
+		month, _ := mapMonth(b.verbal)
+		start = time.Date(y, m+time.Month(sign), 1, 0, 0, 0, 0, loc)
+		for start.Month() != month {
+			start = start.AddDate(0, sign, 0)
 		}
+		length = Period{Months: 1}
+	case "year", "years":
+		start = time.Date(y+sign, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		panic(fmt.Errorf("verbal [%s] not recognized", b.verbal))
 	}
 
-	leftBoundTime, _ := time.Parse(layout, leftBoundString)
-	rightBoundTime, _ := time.Parse(layout, rightBoundString)
+	// a point of a day: "yesterday at 9am"
+	if b.timeOfDay != "" {
+		return atTimeOfDay(start, b.timeOfDay, isExcluded)
+	}
+	return pickPeriodEdge(start, length, isLeftBound, isExcluded)
+}
+
+// truncateWallClock rounds t down to a multiple of the unit on the wall clock in the location of t: 10:45 is 10:00 for
+// hours even in zones with a half-hour offset. The offset of t is kept, so the repeated hour of a DST transition is
+// not confused with the other one.
+func truncateWallClock(t time.Time, unit time.Duration) time.Time {
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(unit).Add(-shift)
+}
+
+// isWhole returns true for the bounds which make a whole window by themselves: "this week" is the window from the
+// beginning of the week to its end, "last 3 weeks" is the window from 3 weeks ago to now, "Q1 2022" is the whole
+// quarter, see resolveWhole
+func (b *boundRelativeToNow) isWhole() bool {
+	return b.this || b.count != 0 || b.year != 0
+}
 
-	// If the period is in the left bound (from yesterday to ...) then we use the right bound of the period
-	// ----[period]-----NOW---
-	//            ^				<-- this bound is used if the period is met in the left window bound
-	// ----NOW------[period]--
-	//              ^			<-- otherwise the left bound is used
+// resolveWhole returns the edges of the window made by a whole period bound. The right edge of a calendar period is
+// its last nanosecond, or the next period start if the bound is excluded. Rolling units are counted between two
+// points, so "last 3 weeks" ends exactly now and "next 3 weeks" starts exactly now.
+func (b *boundRelativeToNow) resolveWhole(n time.Time, isExcluded bool, cal Calendar) (from, to time.Time) {
+	if b.count != 0 {
+		start, length := b.resolveUnits(n, cal)
+		if !b.full {
+			// months are clamped to the end of a month, so the span is not measured back from its start:
+			// "last 3 months" at 31 May starts on 28 February and still ends on 31 May
+			if b.inFuture {
+				return n, length.AddTo(n)
+			}
+			return start, n
+		}
+		return start, pickPeriodEdge(start, length, false, isExcluded)
+	}
+	unit := mapUnitToPeriod(b.verbal)
+	if b.year != 0 {
+		from = cal.startOfNumberedPeriod(b.verbal, b.year, b.index, b.fiscal, n.Location())
+	} else {
+		from = alignToStep(n, unit, cal)
+	}
+	return from, pickPeriodEdge(from, unit, false, isExcluded)
+}
+
+// pickPeriodEdge picks the edge of the period [start, start+length) for a window bound.
+// A period in the left window bound (from yesterday to ...) gives its start, a period in the right window bound
+// (... to today) gives its end, so the window covers both periods whole:
+//
+//	----[period]-----NOW---
+//	    ^                     <-- the period is met in the left window bound
+//	----NOW------[period]--
+//	                    ^     <-- the period is met in the right window bound
+//
+// The end is the last nanosecond of the period, or the next period start if the bound is excluded.
+func pickPeriodEdge(start time.Time, length Period, isLeftBound, isExcluded bool) time.Time {
 	if isLeftBound {
-		return rightBoundTime
+		return start
+	}
+	nextPeriodTime := length.AddTo(start)
+	if isExcluded {
+		return nextPeriodTime
 	}
-	return leftBoundTime
+	return nextPeriodTime.Add(-time.Nanosecond)
+}
+
+// resolveUnits returns the period of several units relative to now.
+// Rolling periods are counted from now: "last 3 weeks" ends now and "next 3 weeks" starts now. Full periods are made
+// of whole calendar units: "last 3 full weeks" ends at the beginning of the current week and "next 3 full weeks"
+// starts at the beginning of the next week.
+func (b *boundRelativeToNow) resolveUnits(n time.Time, cal Calendar) (start time.Time, length Period) {
+	unit := mapUnitToPeriod(b.verbal)
+	length = unit.Times(b.count)
+	if b.full {
+		n = alignToStep(n, unit, cal)
+		if b.inFuture {
+			n = unit.AddTo(n)
+		}
+	}
+	if b.inFuture {
+		return n, length
+	}
+	return length.SubFrom(n), length
 }
 
 // Specification contains left/right bounds for a window that can be resolved to absolute time when needed
 type Specification struct {
 	leftBoundAbs, rightBoundAbs   *time.Time          // "2 April 2022"
-	leftBoundRel, rightBoundRel   *time.Duration      // "3 days"
+	leftBoundRel, rightBoundRel   *Period             // "3 days"
 	leftBoundRelN, rightBoundRelN *boundRelativeToNow // "2 days ago" or "last june"
+	leftExcluded, rightExcluded   bool                // "(from ... to ...)", bounds are included by default
+	step                          *Period             // "by 1 hour", optional
+	sessionGap                    *Period             // "session gap 30 minutes", a session window has no bounds
+	location                      *time.Location      // "in Europe/Berlin", optional, see ResolveAt
 }
 
 func makeSpecification(leftBound, rightBound any) Specification {
@@ -188,8 +428,11 @@ func makeSpecification(leftBound, rightBound any) Specification {
 	switch v := leftBound.(type) {
 	case time.Time:
 		s.leftBoundAbs = &v
-	case time.Duration:
+	case Period:
 		s.leftBoundRel = &v
+	case time.Duration:
+		p := Period{Duration: v}
+		s.leftBoundRel = &p
 	case boundRelativeToNow:
 		s.leftBoundRelN = &v
 	}
@@ -197,8 +440,11 @@ func makeSpecification(leftBound, rightBound any) Specification {
 	switch v := rightBound.(type) {
 	case time.Time:
 		s.rightBoundAbs = &v
-	case time.Duration:
+	case Period:
 		s.rightBoundRel = &v
+	case time.Duration:
+		p := Period{Duration: v}
+		s.rightBoundRel = &p
 	case boundRelativeToNow:
 		s.rightBoundRelN = &v
 	}
@@ -206,78 +452,196 @@ func makeSpecification(leftBound, rightBound any) Specification {
 	return s
 }
 
+// GetStep return the step of buckets the window should be split to ("by 1 hour"), see Window.Split
+func (s *Specification) GetStep() (step Period, ok bool) {
+	if s.step == nil {
+		return Period{}, false
+	}
+	return *s.step, true
+}
+
+// GetLocation return the time zone of the specification ("in Europe/Berlin")
+func (s *Specification) GetLocation() (loc *time.Location, ok bool) {
+	if s.location == nil {
+		return nil, false
+	}
+	return s.location, true
+}
+
 // ResolveAt will generate a new Window instance
-// It resolves all relative time points to absolute ones relatively to the given time point
-func (s *Specification) ResolveAt(t time.Time) *Window {
-	w := Window{}
+// It resolves all relative time points to absolute ones relatively to the given time point.
+// Calendar periods ("yesterday") are resolved in the location of the given time, or in the time zone of the
+// specification if it has one ("in Europe/Berlin").
+// Calendar periods are counted with ISOCalendar unless another Calendar is given: WithCalendar(USCalendar).
+// See Resolve and ResolveNow to resolve at the current time.
+// It panics if the specification can't be resolved, see TryResolveAt.
+func (s *Specification) ResolveAt(t time.Time, opts ...ResolveOption) *Window {
+	w, err := s.TryResolveAt(t, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// TryResolveAt is the same as ResolveAt but returns an error instead of panicking.
+// Errors are one of ErrTwoRelBounds, ErrEmptyWindow, ErrBoundsOrder, ErrSessionHasNoBounds.
+func (s *Specification) TryResolveAt(t time.Time, opts ...ResolveOption) (*Window, error) {
+	config := makeResolveConfig(opts)
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if s.sessionGap != nil {
+		return nil, ErrSessionHasNoBounds
+	}
+	if s.location != nil {
+		t = t.In(s.location)
+	}
+	if config.precision > 0 {
+		t = truncateWallClock(t, config.precision)
+	}
+
+	w := Window{fromExcluded: s.leftExcluded, toExcluded: s.rightExcluded}
 
 	// left bound
 	if s.leftBoundAbs != nil {
 		w.from = s.leftBoundAbs
 	} else if s.leftBoundRel != nil {
 		w.slide = *s.leftBoundRel
-	} else {
-		rt := s.leftBoundRelN.resolveAt(t, true)
+	} else if s.leftBoundRelN != nil {
+		rt := s.leftBoundRelN.resolveAt(t, true, s.leftExcluded, config.calendar)
 		w.from = &rt
 	}
 
 	// right bound
 	if s.rightBoundAbs != nil {
 		w.to = s.rightBoundAbs
-	} else if s.rightBoundRel != nil {
-		rt := w.from.Add(*s.rightBoundRel)
+	} else if s.rightBoundRel != nil && w.from != nil {
+		rt := s.rightBoundRel.AddTo(*w.from)
 		w.to = &rt
 	} else if s.rightBoundRelN != nil {
-		rt := s.rightBoundRelN.resolveAt(t, false)
+		rt := s.rightBoundRelN.resolveAt(t, false, s.rightExcluded, config.calendar)
+		w.to = &rt
+	} else if s.leftBoundRelN != nil && s.leftBoundRelN.isWhole() {
+		// a standalone whole period is the window: "this week", "last 3 weeks", "Q1 2022"
+		_, rt := s.leftBoundRelN.resolveWhole(t, s.rightExcluded, config.calendar)
 		w.to = &rt
 	}
 
 	// edge-case: left bound is a Rel and the right is an Abs, so calculate the left bound abs value relative to the right bound abs value
 	if s.leftBoundRel != nil && w.to != nil {
-		lt := w.to.Add(-*s.leftBoundRel)
+		lt := s.leftBoundRel.SubFrom(*w.to)
 		w.from = &lt
-		w.slide = 0 // reset the slide
+		w.slide = Period{} // reset the slide
 	}
 
-	w.validate()
-	return &w
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	return &w, nil
 }
 
-func (s *Specification) validate() {
+// validate checks that the specification is either a session window or a window with a left bound,
+// specifications made by unmarshaling or NewSpecification are checked the same way as parsed ones
+func (s *Specification) validate() error {
+	if s.sessionGap != nil {
+		if s.Left() != nil || s.Right() != nil || s.step != nil || s.leftExcluded || s.rightExcluded ||
+			s.location != nil {
+			return ErrSessionHasNoBounds
+		}
+		if !isPositive(*s.sessionGap) {
+			return ErrInvalidStep
+		}
+		return nil
+	}
+	if s.Left() == nil {
+		return ErrEmptyWindow
+	}
 	if s.rightBoundRel != nil && s.leftBoundRel != nil {
-		panic(fmt.Errorf("two rel bound are not allowed"))
+		return ErrTwoRelBounds
 	}
+	if s.step != nil && !isPositive(*s.step) {
+		return ErrInvalidStep
+	}
+	return nil
 }
 
 type Window struct {
-	slide    time.Duration
-	from, to *time.Time
+	slide                    Period
+	from, to                 *time.Time
+	fromExcluded, toExcluded bool
 }
 
 // GetBounds return absolute times as left and right bound of the window
+// It panics on sliding windows, see TryGetBounds.
 func (w *Window) GetBounds() (from, to time.Time) {
+	from, to, err := w.TryGetBounds()
+	if err != nil {
+		panic(err)
+	}
+	return from, to
+}
+
+// TryGetBounds is the same as GetBounds but returns ErrSlidingHasNoBounds instead of panicking
+func (w *Window) TryGetBounds() (from, to time.Time, err error) {
+	if w.from == nil || w.to == nil {
+		err = ErrSlidingHasNoBounds
+		return
+	}
+	return *w.from, *w.to, nil
+}
+
+// IncludesFrom return true if the left bound belongs to the window: [from, ...
+func (w *Window) IncludesFrom() bool {
+	return !w.fromExcluded
+}
+
+// IncludesTo return true if the right bound belongs to the window: ..., to]
+func (w *Window) IncludesTo() bool {
+	return !w.toExcluded
+}
+
+// Contains return true if the time point belongs to the window with respect to the bounds inclusivity.
+// Sliding windows contain no points.
+func (w *Window) Contains(t time.Time) bool {
 	if w.from == nil || w.to == nil {
-		panic("absolute bound are not defined on this window")
+		return false
+	}
+	if t.Before(*w.from) || w.fromExcluded && t.Equal(*w.from) {
+		return false
+	}
+	if t.After(*w.to) || w.toExcluded && t.Equal(*w.to) {
+		return false
 	}
-	return *w.from, *w.to
+	return true
 }
 
 // IsSliding return true if the window has no absolute bounds, only the duration
 func (w *Window) IsSliding() bool {
-	return w.slide != 0
+	return !w.slide.IsZero()
 }
 
 // GetSlide return duration of a sliding window
+// Calendar components (days, months, years) are approximated, see Period.Approximate and GetSlidePeriod.
 func (w *Window) GetSlide() time.Duration {
+	return w.slide.Approximate()
+}
+
+// GetSlidePeriod return the exact period of a sliding window
+func (w *Window) GetSlidePeriod() Period {
 	return w.slide
 }
 
-func (w *Window) validate() {
+func (w *Window) validate() error {
 	if w.from != nil && w.to != nil && w.from.After(*w.to) {
-		panic(fmt.Errorf("window bounds are in wrong order"))
+		return ErrBoundsOrder
+	}
+
+	if w.from == nil && w.to == nil && w.slide.IsZero() {
+		return ErrEmptyWindow
 	}
 
-	if w.from == nil && w.to == nil && w.slide == 0 {
-		panic("empty window")
+	if w.from != nil && w.to != nil && w.from.Equal(*w.to) && (w.fromExcluded || w.toExcluded) {
+		return ErrEmptyWindow
 	}
+	return nil
 }
@@ -50,8 +50,12 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	win := winSpec.ResolveAt(now)
+	win, err := winSpec.TryResolveAt(now)
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	fmt.Printf("Canonical form:\t\t%s\n", winSpec)
 	fmt.Printf("Window resolved at:\t%s\n", now.Format("2006-01-02, 15:04:05.000000000 MST"))
 
 	if win.GetSlide() != 0 {
@@ -60,6 +64,17 @@ func main() {
 		l, r := win.GetBounds()
 		fmt.Printf("Left Bound:\t\t%s\n", l.Format("2006-01-02, 15:04:05.000000000 MST"))
 		fmt.Printf("Right Bound:\t\t%s\n", r.Format("2006-01-02, 15:04:05.000000000 MST"))
+
+		if step, ok := winSpec.GetStep(); ok {
+			buckets, err := win.Split(step)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, b := range buckets {
+				l, r := b.GetBounds()
+				fmt.Printf("Bucket:\t\t\t%s - %s\n", l.Format("2006-01-02, 15:04:05 MST"), r.Format("2006-01-02, 15:04:05 MST"))
+			}
+		}
 	}
 }
 
@@ -1,6 +1,7 @@
 package window
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -32,7 +33,7 @@ func Test(t *testing.T) {
 		// 3. Abs-RelN
 		{"1 April 2022 to tomorrow", func() Window {
 			d1 := dateparse.MustParse("1 Apr 2022 00:00:00")
-			d2 := dateparse.MustParse("2 May 2022 00:00:00")
+			d2 := dateparse.MustParse("2 May 2022 23:59:59.999999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		// 4. Rel-Abs
@@ -43,62 +44,144 @@ func Test(t *testing.T) {
 		}},
 		// 5. Rel-Rel (Sliding window)
 		{"30 days", func() Window {
-			return Window{slide: 30 * 24 * time.Hour}
+			return Window{slide: Period{Days: 30}}
+		}},
+		{"within 3 months", func() Window {
+			return Window{slide: Period{Months: 3}}
 		}},
 		// 6. Rel-RelN
 		{"2 days to next week", func() Window {
-			d1 := dateparse.MustParse("30 Apr 2022 00:00:00.000000000")
-			d2 := dateparse.MustParse("2 May 2022 00:00:00.000000000")
+			d1 := dateparse.MustParse("6 May 2022 23:59:59.999999999")
+			d2 := dateparse.MustParse("8 May 2022 23:59:59.999999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"1 days to next day", func() Window {
-			d1 := dateparse.MustParse("1 May 2022 00:00:00.000000000")
-			d2 := dateparse.MustParse("2 May 2022 00:00:00.000000000")
+			d1 := dateparse.MustParse("1 May 2022 23:59:59.999999999")
+			d2 := dateparse.MustParse("2 May 2022 23:59:59.999999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"1 days to next month", func() Window {
-			d1 := dateparse.MustParse("31 May 2022 00:00:00.000000000")
-			d2 := dateparse.MustParse("1 Jun 2022 00:00:00.000000000")
+			d1 := dateparse.MustParse("29 Jun 2022 23:59:59.999999999")
+			d2 := dateparse.MustParse("30 Jun 2022 23:59:59.999999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"30 days to next year", func() Window {
-			d1 := dateparse.MustParse("2 Dec 2022 00:00:00.000000000")
-			d2 := dateparse.MustParse("1 Jan 2023 00:00:00.000000000")
+			d1 := dateparse.MustParse("1 Dec 2023 23:59:59.999999999")
+			d2 := dateparse.MustParse("31 Dec 2023 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"1 quarter to 31 May 2022", func() Window {
+			d1 := dateparse.MustParse("28 Feb 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("31 May 2022 00:00:00.000000000")
 			return Window{from: &d1, to: &d2}
 		}},
 		// 7. RelN-Abs
 		{"next year to 20 May 2024", func() Window {
-			d1 := dateparse.MustParse("31 Dec 2023 23:59:59.999999999")
+			d1 := dateparse.MustParse("1 Jan 2023 00:00:00.000000000")
 			d2 := dateparse.MustParse("20 May 2024 00:00:00.000000000")
 			return Window{from: &d1, to: &d2}
 		}},
 		// 8. RelN-Rel
 		{"next year within 3 days and 2 hours", func() Window {
-			d1 := dateparse.MustParse("31 Dec 2023 23:59:59.999999999")
-			d2 := dateparse.MustParse("4 Jan 2024 01:59:59.999999999")
+			d1 := dateparse.MustParse("1 Jan 2023 00:00:00.000000000")
+			d2 := dateparse.MustParse("4 Jan 2023 02:00:00.000000000")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"today within 1 minute", func() Window {
-			d1 := dateparse.MustParse("1 May 2022 23:59:59.999999999")
-			d2 := dateparse.MustParse("2 May 2022 00:00:59.999999999")
+			d1 := dateparse.MustParse("1 May 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("1 May 2022 00:01:00.000000000")
 			return Window{from: &d1, to: &d2}
 		}},
 		// 9. RelN-RelN
 		{"last hour to next minute", func() Window {
-			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.999999999")
-			d2 := dateparse.MustParse("1 May 2022 00:01:00.000000000")
+			d1 := dateparse.MustParse("30 Apr 2022 23:00:00.000000000")
+			d2 := dateparse.MustParse("1 May 2022 00:01:59.999999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"last second to next millisecond", func() Window {
-			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.999999999")
-			d2 := dateparse.MustParse("1 May 2022 00:00:00.001000000")
+			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.000000000")
+			d2 := dateparse.MustParse("1 May 2022 00:00:00.001999999")
 			return Window{from: &d1, to: &d2}
 		}},
 		{"last microsecond to next nanosecond", func() Window {
-			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.999999999")
+			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.999999000")
 			d2 := dateparse.MustParse("1 May 2022 00:00:00.000000001")
 			return Window{from: &d1, to: &d2}
 		}},
+		{"1 year ago to now", func() Window {
+			d1 := dateparse.MustParse("1 May 2021 00:00:00.000000000")
+			d2 := dateparse.MustParse("1 May 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"2 months and 1 day ago to 1 week later", func() Window {
+			d1 := dateparse.MustParse("28 Feb 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("8 May 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last friday to today", func() Window {
+			d1 := dateparse.MustParse("29 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("1 May 2022 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last sunday to next sunday", func() Window {
+			d1 := dateparse.MustParse("24 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("8 May 2022 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last march to next june", func() Window {
+			d1 := dateparse.MustParse("1 Mar 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("30 Jun 2022 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last may to next may", func() Window {
+			d1 := dateparse.MustParse("1 May 2021 00:00:00.000000000")
+			d2 := dateparse.MustParse("31 May 2023 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last february to next week", func() Window {
+			d1 := dateparse.MustParse("1 Feb 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("8 May 2022 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"last month to today", func() Window {
+			d1 := dateparse.MustParse("1 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("1 May 2022 23:59:59.999999999")
+			return Window{from: &d1, to: &d2}
+		}},
+		// an anchored point without the other bound
+		{"3 days before 2022-04-10", func() Window {
+			d1 := dateparse.MustParse("7 Apr 2022 00:00:00.000000000")
+			return Window{from: &d1}
+		}},
+		// bounds inclusivity
+		{"[1 April 2022 to 2 April 2022]", func() Window {
+			d1 := dateparse.MustParse("01 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("2 Apr 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2}
+		}},
+		{"[from 1 April 2022 to 2 April 2022)", func() Window {
+			d1 := dateparse.MustParse("01 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("2 Apr 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2, toExcluded: true}
+		}},
+		{"(last month to next month)", func() Window {
+			d1 := dateparse.MustParse("1 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("1 Jul 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2, fromExcluded: true, toExcluded: true}
+		}},
+		{"(last second to next millisecond]", func() Window {
+			d1 := dateparse.MustParse("30 Apr 2022 23:59:59.000000000")
+			d2 := dateparse.MustParse("1 May 2022 00:00:00.001999999")
+			return Window{from: &d1, to: &d2, fromExcluded: true}
+		}},
+		{"[ 1 April 2022 within 1 day )", func() Window {
+			d1 := dateparse.MustParse("01 Apr 2022 00:00:00.000000000")
+			d2 := dateparse.MustParse("2 Apr 2022 00:00:00.000000000")
+			return Window{from: &d1, to: &d2, toExcluded: true}
+		}},
+		{"[30 days)", func() Window {
+			return Window{slide: Period{Days: 30}, toExcluded: true}
+		}},
 	}
 
 	for i, tt := range tests {
@@ -116,3 +199,261 @@ func Test(t *testing.T) {
 
 	}
 }
+
+func Test_resolveBounds(t *testing.T) {
+	type test struct {
+		zone, now string // the window is resolved at now in the zone, the local one if it is empty
+		text      string
+		from, to  string
+	}
+	tests := []test{
+		// last N units and next N units, 2022-04-13 is Wednesday
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to last 3 weeks", "2022-01-01 00:00:00", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "(from last 3 weeks to 2023-01-01]", "2022-03-23 15:04:05", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to last 3 full weeks", "2022-01-01 00:00:00", "2022-04-10 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "(from last 3 full weeks to 2023-01-01]", "2022-03-21 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from last 3 full weeks to 2023-01-01", "2022-03-21 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to next 2 full days", "2022-01-01 00:00:00", "2022-04-15 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "(from next 2 full days to 2023-01-01]", "2022-04-14 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "(from next 2 days to 2023-01-01]", "2022-04-13 15:04:05", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to last 7d", "2022-01-01 00:00:00", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to last 2 full months", "2022-01-01 00:00:00", "2022-03-31 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "(from last full year to 2023-01-01]", "2021-01-01 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to next 90 full minutes", "2022-01-01 00:00:00", "2022-04-13 16:34:59.999999999"},
+		{"", "2022-04-13 15:04:05", "from last week to this week", "2022-04-04 00:00:00", "2022-04-17 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "[from last week to this week)", "2022-04-04 00:00:00", "2022-04-18 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to this month", "2022-01-01 00:00:00", "2022-04-30 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to this week", "2022-01-01 00:00:00", "2022-04-17 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "(from this month to 2023-01-01]", "2022-04-01 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to this quarter", "2022-01-01 00:00:00", "2022-06-30 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "from this year to 2023-01-01", "2022-01-01 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2021-01-01 to last 2 quarters", "2021-01-01 00:00:00", "2022-04-13 15:04:05"},
+		// whole periods
+		{"", "2022-04-13 15:04:05", "this week", "2022-04-11 00:00:00", "2022-04-17 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "this quarter to now", "2022-04-01 00:00:00", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "from this week to now", "2022-04-11 00:00:00", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "[this month)", "2022-04-01 00:00:00", "2022-05-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "this hour", "2022-04-13 15:00:00", "2022-04-13 15:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "last 3 weeks", "2022-03-23 15:04:05", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "last 3 months", "2022-01-13 15:04:05", "2022-04-13 15:04:05"},
+		{"UTC", "2022-05-31T12:00:00Z", "last 3 months", "2022-02-28T12:00:00Z", "2022-05-31T12:00:00Z"},
+		{"UTC", "2022-05-31T12:00:00Z", "next 1 month", "2022-05-31T12:00:00Z", "2022-06-30T12:00:00Z"},
+		{"", "2022-04-13 15:04:05", "from last 3 weeks to now", "2022-03-23 15:04:05", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "last 3 days to today", "2022-04-10 15:04:05", "2022-04-13 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "next 2 days", "2022-04-13 15:04:05", "2022-04-15 15:04:05"},
+		{"", "2022-04-13 15:04:05", "last 1.5 hours", "2022-04-13 13:34:05", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "next 0.5 weeks", "2022-04-13 15:04:05", "2022-04-17 03:04:05"},
+		{"", "2022-04-13 15:04:05", "last 2 full weeks", "2022-03-28 00:00:00", "2022-04-10 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "[next 2 full days)", "2022-04-14 00:00:00", "2022-04-16 00:00:00"},
+		{"", "2022-04-13 15:04:05", "Q1 2022", "2022-01-01 00:00:00", "2022-03-31 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "from Q1 2022 to now", "2022-01-01 00:00:00", "2022-04-13 15:04:05"},
+		{"", "2022-04-13 15:04:05", "[2021-H2)", "2021-07-01 00:00:00", "2022-01-01 00:00:00"},
+		// quarters and halves
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to last quarter", "2021-01-01 00:00:00", "2022-03-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from last quarter to 2023-01-01", "2022-01-01 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to next quarter", "2021-01-01 00:00:00", "2022-09-30 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to last half", "2021-01-01 00:00:00", "2021-12-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "(from next half to 2024-01-01]", "2022-07-01 00:00:00", "2024-01-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to this half", "2021-01-01 00:00:00", "2022-06-30 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to last 2 full halves", "2021-01-01 00:00:00", "2021-12-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from Q1 2022 to Q2 2022", "2022-01-01 00:00:00", "2022-06-30 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "[from Q1 2022 to Q2 2022)", "2022-01-01 00:00:00", "2022-07-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "from last quarter to this quarter", "2022-01-01 00:00:00", "2022-06-30 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from H1 2021 to last half", "2021-01-01 00:00:00", "2021-12-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to Q1 2022", "2021-01-01 00:00:00", "2022-03-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from 2022-Q3 to 2024-01-01", "2022-07-01 00:00:00", "2024-01-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "(from 2022q4 to 2024-01-01]", "2022-10-01 00:00:00", "2024-01-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to H2 2021", "2021-01-01 00:00:00", "2021-12-31 23:59:59.999999999"},
+		{"", "2022-05-13 15:04:05", "from 2021-h1 to 2024-01-01", "2021-01-01 00:00:00", "2024-01-01 00:00:00"},
+		{"", "2022-05-13 15:04:05", "from 2021-01-01 to fiscal Q1 2022", "2021-01-01 00:00:00", "2022-03-31 23:59:59.999999999"}, // fiscal years start in January by default
+		// time zones
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "from yesterday to today", "2022-04-12T00:00:00-04:00", "2022-04-13T23:59:59.999999999-04:00"},
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "from yesterday to today in Europe/Berlin", "2022-04-13T00:00:00+02:00", "2022-04-14T23:59:59.999999999+02:00"},
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "from 2022-04-01 to 2022-04-02 TZ=America/Denver", "2022-04-01T00:00:00-06:00", "2022-04-02T00:00:00-06:00"},
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "from 2022-04-01T00:00:00Z to today in Asia/Tokyo", "2022-04-01T00:00:00Z", "2022-04-14T23:59:59.999999999+09:00"},
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "[MTD) in UTC", "2022-04-01T00:00:00Z", "2022-04-13T23:30:00Z"},
+		{"America/New_York", "2022-04-13T19:30:00-04:00", "from 2 days after 2022-04-01 to now in Europe/Berlin", "2022-04-03T00:00:00+02:00", "2022-04-13T23:30:00Z"},
+		// DST
+		// DST starts on 2022-03-13 02:00 and ends on 2022-11-06 02:00
+		{"America/New_York", "2022-03-14T12:00:00-04:00", "from yesterday to 2100-01-01", "2022-03-13T00:00:00-05:00", "2100-01-01"},
+		{"America/New_York", "2022-03-14T12:00:00-04:00", "[from 2000-01-01 to yesterday)", "2000-01-01", "2022-03-14T00:00:00-04:00"},
+		{"America/New_York", "2022-03-14T12:00:00-04:00", "from 2000-01-01 to yesterday", "2000-01-01", "2022-03-13T23:59:59.999999999-04:00"},
+		{"America/New_York", "2022-03-13T12:00:00-04:00", "from today to 2100-01-01", "2022-03-13T00:00:00-05:00", "2100-01-01"},
+		{"America/New_York", "2022-03-14T12:00:00-04:00", "from yesterday at 1am to 2100-01-01", "2022-03-13T01:00:00-05:00", "2100-01-01"},
+		{"America/New_York", "2022-03-14T12:00:00-04:00", "from 2000-01-01 to yesterday 22:00", "2000-01-01", "2022-03-13T22:00:00-04:00"},
+		{"America/New_York", "2022-11-06T01:30:00-05:00", "from last hour to 2100-01-01", "2022-11-06T01:00:00-04:00", "2100-01-01"},
+		{"America/New_York", "2022-11-06T01:30:00-05:00", "from this hour to 2100-01-01", "2022-11-06T01:00:00-05:00", "2100-01-01"},
+		{"America/New_York", "2022-11-06T01:30:00-05:00", "from today to 2100-01-01", "2022-11-06T00:00:00-04:00", "2100-01-01"},
+		{"America/New_York", "2022-11-06T01:30:00-05:00", "[from 2000-01-01 to today)", "2000-01-01", "2022-11-07T00:00:00-05:00"},
+		// DST starts on 2022-03-27 01:00 and ends on 2022-10-30 02:00
+		{"Europe/London", "2022-03-28T09:00:00+01:00", "from last sunday to 2100-01-01", "2022-03-27T00:00:00Z", "2100-01-01"},
+		{"Europe/London", "2022-03-28T09:00:00+01:00", "[from 2000-01-01 to last sunday)", "2000-01-01", "2022-03-28T00:00:00+01:00"},
+		{"Europe/London", "2022-10-31T09:00:00Z", "from last month to 2100-01-01", "2022-09-01T00:00:00+01:00", "2100-01-01"},
+		{"Europe/London", "2022-10-31T09:00:00Z", "from last week to 2100-01-01", "2022-10-24T00:00:00+01:00", "2100-01-01"},
+		{"Europe/London", "2022-10-31T09:00:00Z", "[from 2000-01-01 to last week)", "2000-01-01", "2022-10-31T00:00:00Z"},
+		{"Europe/London", "2022-10-29T09:00:00+01:00", "[from 2000-01-01 to tomorrow)", "2000-01-01", "2022-10-31T00:00:00Z"},
+		// DST shifts clocks by 30 minutes, it ends on 2022-04-03 02:00 and starts on 2022-10-02 02:00
+		{"Australia/Lord_Howe", "2022-04-03T12:00:00+10:30", "from yesterday to 2100-01-01", "2022-04-02T00:00:00+11:00", "2100-01-01"},
+		{"Australia/Lord_Howe", "2022-04-03T12:00:00+10:30", "[from 2000-01-01 to yesterday)", "2000-01-01", "2022-04-03T00:00:00+11:00"},
+		{"Australia/Lord_Howe", "2022-04-03T12:00:00+10:30", "[from 2000-01-01 to today)", "2000-01-01", "2022-04-04T00:00:00+10:30"},
+		{"Australia/Lord_Howe", "2022-10-02T03:15:00+11:00", "from last hour to 2100-01-01", "2022-10-02T01:00:00+10:30", "2100-01-01"},
+		{"Australia/Lord_Howe", "2022-10-02T03:15:00+11:00", "from next hour to 2100-01-01", "2022-10-02T04:00:00+11:00", "2100-01-01"},
+		{"Australia/Lord_Howe", "2022-10-01T12:00:00+10:30", "from next day to 2100-01-01", "2022-10-02T00:00:00+10:30", "2100-01-01"},
+		{"Australia/Lord_Howe", "2022-10-01T12:00:00+10:30", "[from 2000-01-01 to next day)", "2000-01-01", "2022-10-03T00:00:00+11:00"},
+		// anchored offsets
+		{"", "2022-04-13 15:04:05", "from 3 days before last monday to 2023-01-01", "2022-04-08 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to 2 hours after tomorrow", "2022-01-01 00:00:00", "2022-04-15 02:00:00"},
+		{"", "2022-04-13 15:04:05", "from 1 hour after 2 days before last monday to 2023-01-01", "2022-04-09 01:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2 days after 2022-04-01 to 2023-01-01", "2022-04-03 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to 1 day after now/d", "2022-01-01 00:00:00", "2022-04-15 00:00:00"},
+		{"", "2022-04-13 15:04:05", "(from 1 day before now/d to 2023-01-01]", "2022-04-12 00:00:00", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to 1 week before this month", "2022-01-01 00:00:00", "2022-03-25 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from 2022-01-01 to 1 day after 2 days ago", "2022-01-01 00:00:00", "2022-04-12 15:04:05"},
+		{"", "2022-04-13 15:04:05", "(from 1 day after last 3 weeks to 2023-01-01]", "2022-04-14 15:04:05", "2023-01-01 00:00:00"},
+		{"", "2022-04-13 15:04:05", "within 3 days before 2022-05-01", "2022-04-10 15:04:05", "2022-05-01 00:00:00"},
+		// time of day
+		{"", "2022-04-13 15:04:05", "from yesterday 22:00 to today 06:00", "2022-04-12 22:00:00", "2022-04-13 06:00:00"},
+		{"", "2022-04-13 15:04:05", "from yesterday at 9am to today at 9:30:15 pm", "2022-04-12 09:00:00", "2022-04-13 21:30:15"},
+		{"", "2022-04-13 15:04:05", "from yesterday at 12am to today at 12pm", "2022-04-12 00:00:00", "2022-04-13 12:00:00"},
+		{"", "2022-04-13 15:04:05", "from last monday noon to tomorrow midnight", "2022-04-11 12:00:00", "2022-04-14 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from last day at 10:00 to next friday at 5 PM", "2022-04-12 10:00:00", "2022-04-15 17:00:00"},
+		{"", "2022-04-13 15:04:05", "[from yesterday start of day to yesterday end of day)", "2022-04-12 00:00:00", "2022-04-13 00:00:00"},
+		{"", "2022-04-13 15:04:05", "from yesterday start of day to yesterday end of day", "2022-04-12 00:00:00", "2022-04-12 23:59:59.999999999"},
+		{"", "2022-04-13 15:04:05", "from 2 hours after yesterday 9am to today", "2022-04-12 11:00:00", "2022-04-13 23:59:59.999999999"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			loc := time.Local
+			if tt.zone != "" {
+				var err error
+				if loc, err = time.LoadLocation(tt.zone); err != nil {
+					t.Skip(err)
+				}
+			}
+			winSpec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := winSpec.ResolveAt(dateparse.MustParse(tt.now).In(loc)).GetBounds()
+			expectedFrom, expectedTo := dateparse.MustParse(tt.from), dateparse.MustParse(tt.to)
+			if !from.Equal(expectedFrom) || !to.Equal(expectedTo) {
+				t.Errorf("window [%s, %s] should be [%s, %s]", from, to, expectedFrom, expectedTo)
+			}
+		})
+	}
+}
+
+func Test_resolveToDate(t *testing.T) {
+	now := dateparse.MustParse("2022-04-13 15:04:05") // Wednesday
+
+	type test struct {
+		text     string
+		expected string // the left bound, the right one is now
+	}
+	tests := []test{
+		{"WTD", "2022-04-11 00:00:00"},
+		{"week to date", "2022-04-11 00:00:00"},
+		{"mtd", "2022-04-01 00:00:00"},
+		{"Quarter To Date", "2022-04-01 00:00:00"},
+		{"[YTD) by 1 month", "2022-01-01 00:00:00"},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			winSpec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, to := winSpec.ResolveAt(now).GetBounds()
+			if expected := dateparse.MustParse(tt.expected); !from.Equal(expected) || !to.Equal(now) {
+				t.Errorf("window [%s, %s] should be [%s, %s]", from, to, expected, now)
+			}
+		})
+	}
+}
+
+func Test_resolveFail(t *testing.T) {
+	now := dateparse.MustParse("1 May 2022 00:00:00")
+
+	type test struct {
+		specFunc func() Specification
+		err      error
+	}
+	tests := []test{
+		{func() Specification {
+			s, _ := Start("2 May 2022 to 1 May 2022")
+			return s
+		}, ErrBoundsOrder},
+		{func() Specification {
+			s, _ := Start("tomorrow to yesterday")
+			return s
+		}, ErrBoundsOrder},
+		{func() Specification { return Specification{} }, ErrEmptyWindow},
+		{func() Specification {
+			s, _ := Start("(now to now)")
+			return s
+		}, ErrEmptyWindow},
+		{func() Specification { return makeSpecification(time.Hour, time.Hour) }, ErrTwoRelBounds},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec := tt.specFunc()
+			_, err := spec.TryResolveAt(now)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error [%v] should be [%v]", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_slidingHasNoBounds(t *testing.T) {
+	spec, err := Start("30 days")
+	if err != nil {
+		t.Fatal(err)
+	}
+	win, err := spec.TryResolveAt(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = win.TryGetBounds(); !errors.Is(err, ErrSlidingHasNoBounds) {
+		t.Errorf("error [%v] should be [%v]", err, ErrSlidingHasNoBounds)
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	now := dateparse.MustParse("1 May 2022 00:00:00")
+
+	type test struct {
+		text     string
+		point    string
+		contains bool
+	}
+	tests := []test{
+		{"1 April 2022 to 2 April 2022", "1 Apr 2022 00:00:00", true},
+		{"1 April 2022 to 2 April 2022", "2 Apr 2022 00:00:00", true},
+		{"1 April 2022 to 2 April 2022", "2 Apr 2022 00:00:00.000000001", false},
+		{"[1 April 2022 to 2 April 2022)", "2 Apr 2022 00:00:00", false},
+		{"[1 April 2022 to 2 April 2022)", "1 Apr 2022 23:59:59.999999999", true},
+		{"(1 April 2022 to 2 April 2022]", "1 Apr 2022 00:00:00", false},
+		{"(last month to next month)", "1 Apr 2022 00:00:00", false},
+		{"(last month to next month)", "1 Apr 2022 00:00:00.000000001", true},
+		{"(last month to next month)", "30 Jun 2022 23:59:59.999999999", true},
+		{"(last month to next month)", "1 Jul 2022 00:00:00", false},
+		{"30 days", "1 May 2022 00:00:00", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("test %d", i), func(t *testing.T) {
+			spec, err := Start(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			win := spec.ResolveAt(now)
+			if c := win.Contains(dateparse.MustParse(tt.point)); c != tt.contains {
+				t.Errorf("window %v contains [%s]: %t, expected %t", win, tt.point, c, tt.contains)
+			}
+		})
+	}
+}